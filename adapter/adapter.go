@@ -18,6 +18,21 @@ var videoStandardOption = coreif.CoreOption{
 	PerGame:     true,
 }
 
+// channelVolumeOption builds the volume option for one PSG channel.
+func channelVolumeOption(key, label string) coreif.CoreOption {
+	return coreif.CoreOption{
+		Key:         key,
+		Label:       label,
+		Description: "Volume percentage for this PSG channel (0 mutes)",
+		Type:        coreif.CoreOptionRange,
+		Default:     "100",
+		Min:         0,
+		Max:         100,
+		Step:        10,
+		Category:    coreif.CoreOptionCategoryAudio,
+	}
+}
+
 // Factory implements CoreFactory for the SMS emulator.
 type Factory struct{}
 
@@ -42,7 +57,7 @@ func (f *Factory) SystemInfo() coreif.SystemInfo {
 		// The PAL master clock differs by <1%, so this value is used
 		// for both NTSC and PAL.
 		PixelAspectRatio: 8.0 / 7.0,
-		SampleRate:       48000,
+		SampleRate:       core.SampleRate,
 		Buttons: []coreif.Button{
 			{Name: "1", ID: 4, DefaultKey: "J", DefaultPad: "A"},
 			{Name: "2", ID: 5, DefaultKey: "K", DefaultPad: "B"},
@@ -58,7 +73,111 @@ func (f *Factory) SystemInfo() coreif.SystemInfo {
 				Default:     "false",
 				Category:    coreif.CoreOptionCategoryVideo,
 			},
+			{
+				Key:         "palette",
+				Label:       "Palette",
+				Description: "Color palette used for rendering",
+				Type:        coreif.CoreOptionSelect,
+				Default:     "original",
+				Values:      []string{"original", "contrast"},
+				Category:    coreif.CoreOptionCategoryVideo,
+				PerGame:     true,
+			},
+			{
+				Key:         "video_filter",
+				Label:       "Video Filter",
+				Description: "Post-processing applied to each frame",
+				Type:        coreif.CoreOptionSelect,
+				Default:     "none",
+				Values:      []string{"none", "scanlines", "phosphor", "ntsc"},
+				Category:    coreif.CoreOptionCategoryVideo,
+			},
+			{
+				Key:         "frame_blend",
+				Label:       "Frame Blending",
+				Description: "Average consecutive frames so flicker transparency looks solid",
+				Type:        coreif.CoreOptionBool,
+				Default:     "false",
+				Category:    coreif.CoreOptionCategoryVideo,
+				PerGame:     true,
+			},
+			{
+				Key:         "no_sprite_limit",
+				Label:       "Remove Sprite Limit",
+				Description: "Draw more than 8 sprites per line to reduce flicker",
+				Type:        coreif.CoreOptionBool,
+				Default:     "false",
+				Category:    coreif.CoreOptionCategoryVideo,
+				PerGame:     true,
+			},
+			{
+				Key:         "midline_writes",
+				Label:       "Mid-Scanline VDP Writes",
+				Description: "Apply scroll and palette changes at the pixel they occur (slower)",
+				Type:        coreif.CoreOptionBool,
+				Default:     "false",
+				Category:    coreif.CoreOptionCategoryCore,
+				PerGame:     true,
+			},
+			{
+				Key:         "blanked_sprite_collision",
+				Label:       "Sprite Collision While Blanked",
+				Description: "Evaluate sprite collision and overflow while the display is blanked",
+				Type:        coreif.CoreOptionBool,
+				Default:     "false",
+				Category:    coreif.CoreOptionCategoryCore,
+				PerGame:     true,
+			},
+			{
+				Key:         "vram_access_timing",
+				Label:       "VRAM Access Timing",
+				Description: "Lose VRAM writes made too fast during active display, as on hardware",
+				Type:        coreif.CoreOptionBool,
+				Default:     "false",
+				Category:    coreif.CoreOptionCategoryCore,
+				PerGame:     true,
+			},
+			{
+				Key:         "mapper",
+				Label:       "Cartridge Mapper",
+				Description: "Override the detected mapper for dumps missing from the ROM database (reload the game after changing)",
+				Type:        coreif.CoreOptionSelect,
+				Default:     "auto",
+				Values:      []string{"auto", "sega", "codemasters"},
+				Category:    coreif.CoreOptionCategoryCore,
+				PerGame:     true,
+			},
+			{
+				Key:         "frameskip",
+				Label:       "Frameskip",
+				Description: "Frames skipped after each drawn frame to save CPU time",
+				Type:        coreif.CoreOptionSelect,
+				Default:     "0",
+				Values:      []string{"0", "1", "2", "3"},
+				Category:    coreif.CoreOptionCategoryCore,
+			},
 			videoStandardOption,
+			{
+				Key:         "swap_buttons",
+				Label:       "Swap Buttons",
+				Description: "Exchange buttons 1 and 2 on both controllers",
+				Type:        coreif.CoreOptionBool,
+				Default:     "false",
+				Category:    coreif.CoreOptionCategoryInput,
+				PerGame:     true,
+			},
+			{
+				Key:         "audio_lowpass",
+				Label:       "Low-Pass Filter",
+				Description: "Soften the PSG output like the console's audio stage",
+				Type:        coreif.CoreOptionBool,
+				Default:     "false",
+				Category:    coreif.CoreOptionCategoryAudio,
+			},
+			channelVolumeOption("volume_tone0", "Tone 0 Volume"),
+			channelVolumeOption("volume_tone1", "Tone 1 Volume"),
+			channelVolumeOption("volume_tone2", "Tone 2 Volume"),
+			channelVolumeOption("volume_noise", "Noise Volume"),
 		},
 		MetadataVariants: []coreif.MetadataVariant{
 			{Name: "Master System", RDBName: "Sega - Master System - Mark III", ThumbnailRepo: "Sega_-_Master_System_-_Mark_III"},
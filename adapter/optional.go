@@ -0,0 +1,105 @@
+package adapter
+
+import (
+	"github.com/user-none/eblitui/coreif"
+	"github.com/user-none/emkiii/core"
+)
+
+// Optional interfaces implemented by the emulators this adapter creates,
+// or by Factory where noted, for features coreif has no interface for.
+// Frontends check for them with a type assertion, as they do for
+// coreif.SaveStater, and keep their coreif behavior when the assertion
+// fails.
+
+// PixelFormatter selects the format GetFramebuffer returns, so a frontend
+// can take RGB565 or XRGB8888 frames without converting them.
+type PixelFormatter interface {
+	SetPixelFormat(f core.PixelFormat)
+}
+
+// FrameViewer returns the frame without copying. With crop border
+// active the pitch is wider than width, so a frontend that takes the view
+// must pass the pitch on rather than derive the width from it.
+type FrameViewer interface {
+	FrameView() (pixels []byte, width, height, pitch int)
+}
+
+// FrameEventer reports what happened during the last RunFrame, such as
+// battery save writes and pause presses, so a frontend can blink an LED
+// or rumble.
+type FrameEventer interface {
+	FrameEvents() core.FrameEvent
+}
+
+// SRAMFlusher reports unsaved battery RAM, so a frontend can write the
+// save to disk a couple of seconds after the game stops writing it
+// instead of only on exit. After writing GetSRAM the frontend calls
+// MarkSRAMSaved.
+type SRAMFlusher interface {
+	SRAMDirty() bool
+	SRAMFlushDue() bool
+	MarkSRAMSaved()
+}
+
+// FastStater takes headerless in-memory snapshots for rollback netplay
+// and run-ahead. A frontend allocates FastStateSize bytes once and reuses
+// the buffer. See core.Emulator.SerializeFast.
+type FastStater interface {
+	FastStateSize() int
+	SerializeFast(data []byte) error
+	DeserializeFast(data []byte) error
+}
+
+// HomebrewHeaderReader is implemented by the factory. It reads the SDSC
+// header of homebrew ROMs while scanning a library, so a frontend can
+// show a name and author for ROMs missing from its game database.
+type HomebrewHeaderReader interface {
+	SDSCHeader(rom []byte) (core.SDSCHeader, bool)
+}
+
+// SRAMImporter is implemented by the factory. It converts a battery save
+// written by another SMS emulator (.srm or .sav) into this core's save
+// layout, for a frontend that imports saves into its library.
+type SRAMImporter interface {
+	ImportSRAM(data []byte) ([]byte, error)
+}
+
+// Emulator is every interface the emulators this adapter creates
+// implement. A wrapper that overrides a few methods embeds it, instead of
+// coreif.Emulator, so the frontend's type assertions for the optional
+// interfaces still succeed on the wrapper.
+type Emulator interface {
+	coreif.Emulator
+	coreif.SaveStater
+	coreif.BatterySaver
+	coreif.MemoryInspector
+	coreif.MemoryMapper
+	PixelFormatter
+	FrameViewer
+	FrameEventer
+	SRAMFlusher
+	FastStater
+}
+
+var (
+	_ Emulator             = (*core.Emulator)(nil)
+	_ Emulator             = (*recordingEmulator)(nil)
+	_ PixelFormatter       = (*core.Emulator)(nil)
+	_ FrameViewer          = (*core.Emulator)(nil)
+	_ FrameEventer         = (*core.Emulator)(nil)
+	_ SRAMFlusher          = (*core.Emulator)(nil)
+	_ FastStater           = (*core.Emulator)(nil)
+	_ HomebrewHeaderReader = (*Factory)(nil)
+	_ SRAMImporter         = (*Factory)(nil)
+)
+
+// SDSCHeader returns the SDSC header of rom, if it has one.
+func (f *Factory) SDSCHeader(rom []byte) (core.SDSCHeader, bool) {
+	return core.ParseSDSCHeader(rom)
+}
+
+// ImportSRAM converts a battery save from another emulator. See
+// core.ImportSRAM.
+func (f *Factory) ImportSRAM(data []byte) ([]byte, error) {
+	return core.ImportSRAM(data)
+}
@@ -0,0 +1,241 @@
+package adapter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/user-none/eblitui/coreif"
+	"github.com/user-none/emkiii/core"
+)
+
+// WAV output format, matching GetAudioSamples
+const (
+	wavChannels      = 2
+	wavBitsPerSample = 16
+	wavHeaderSize    = 44
+)
+
+// WAVWriter streams 16-bit stereo PCM to a WAV file. The RIFF and data
+// chunk sizes are written as zero and patched on Close.
+type WAVWriter struct {
+	f          *os.File
+	sampleRate int
+	dataBytes  uint32
+	buf        []byte
+}
+
+// NewWAVWriter creates path and writes the WAV header.
+func NewWAVWriter(path string, sampleRate int) (*WAVWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &WAVWriter{f: f, sampleRate: sampleRate}
+	if _, err := f.Write(w.header()); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// header builds the 44-byte canonical WAV header for the data written so far
+func (w *WAVWriter) header() []byte {
+	blockAlign := wavChannels * wavBitsPerSample / 8
+	h := make([]byte, wavHeaderSize)
+	copy(h[0:], "RIFF")
+	binary.LittleEndian.PutUint32(h[4:], 36+w.dataBytes)
+	copy(h[8:], "WAVE")
+	copy(h[12:], "fmt ")
+	binary.LittleEndian.PutUint32(h[16:], 16) // PCM fmt chunk size
+	binary.LittleEndian.PutUint16(h[20:], 1)  // PCM
+	binary.LittleEndian.PutUint16(h[22:], wavChannels)
+	binary.LittleEndian.PutUint32(h[24:], uint32(w.sampleRate))
+	binary.LittleEndian.PutUint32(h[28:], uint32(w.sampleRate*blockAlign))
+	binary.LittleEndian.PutUint16(h[32:], uint16(blockAlign))
+	binary.LittleEndian.PutUint16(h[34:], wavBitsPerSample)
+	copy(h[36:], "data")
+	binary.LittleEndian.PutUint32(h[40:], w.dataBytes)
+	return h
+}
+
+// WriteSamples appends interleaved stereo samples.
+func (w *WAVWriter) WriteSamples(samples []int16) error {
+	if cap(w.buf) < len(samples)*2 {
+		w.buf = make([]byte, len(samples)*2)
+	}
+	buf := w.buf[:len(samples)*2]
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(buf[i*2:], uint16(s))
+	}
+	n, err := w.f.Write(buf)
+	w.dataBytes += uint32(n)
+	return err
+}
+
+// Close patches the chunk sizes and closes the file.
+func (w *WAVWriter) Close() error {
+	if _, err := w.f.WriteAt(w.header(), 0); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// RecordConfig selects what a recording factory captures.
+type RecordConfig struct {
+	// AudioPath, when set, receives all audio as a WAV file.
+	AudioPath string
+	// FrameDir, when set, receives one PNG per emulated frame
+	// (frame_000000.png, ...). Frames and audio are written together at
+	// the end of each RunFrame, so the two streams stay in sync.
+	FrameDir string
+}
+
+// RecordingFactory wraps Factory so emulators it creates record their
+// output while running.
+type RecordingFactory struct {
+	Factory
+	Config RecordConfig
+}
+
+// CreateEmulator creates an emulator that records to the configured
+// outputs. Recording stops and files are finalized when the emulator is
+// closed.
+func (f *RecordingFactory) CreateEmulator(rom []byte) (coreif.Emulator, error) {
+	emu, err := f.Factory.CreateEmulator(rom)
+	if err != nil {
+		return nil, err
+	}
+	inner, ok := emu.(*core.Emulator)
+	if !ok {
+		emu.Close()
+		return nil, fmt.Errorf("recording: unsupported emulator type %T", emu)
+	}
+	r := &recordingEmulator{Emulator: inner, frameDir: f.Config.FrameDir}
+
+	if f.Config.FrameDir != "" {
+		if err := os.MkdirAll(f.Config.FrameDir, 0o755); err != nil {
+			inner.Close()
+			return nil, err
+		}
+	}
+	if f.Config.AudioPath != "" {
+		r.wav, err = NewWAVWriter(f.Config.AudioPath, f.SystemInfo().SampleRate)
+		if err != nil {
+			inner.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// recordingEmulator captures each frame's audio and video after it runs.
+// Embedding the core emulator keeps the save state, SRAM and memory
+// interfaces available to the frontend.
+type recordingEmulator struct {
+	*core.Emulator
+	wav      *WAVWriter
+	frameDir string
+	frame    int
+	encoder  png.Encoder
+	failed   bool
+}
+
+// RunFrame runs one frame and appends it to the recording.
+func (r *recordingEmulator) RunFrame() {
+	r.Emulator.RunFrame()
+	if r.failed {
+		return
+	}
+
+	if r.wav != nil {
+		if err := r.wav.WriteSamples(r.GetAudioSamples()); err != nil {
+			r.fail(err)
+			return
+		}
+	}
+
+	if r.frameDir != "" {
+		if err := r.writeFrame(); err != nil {
+			r.fail(err)
+			return
+		}
+	}
+	r.frame++
+}
+
+// writeFrame saves the current framebuffer as the next PNG in the sequence
+func (r *recordingEmulator) writeFrame() error {
+	img := frameImage(r.GetFramebuffer(), r.GetFramebufferStride(), r.GetActiveHeight(), r.PixelFormat())
+
+	path := filepath.Join(r.frameDir, fmt.Sprintf("frame_%06d.png", r.frame))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	r.encoder.CompressionLevel = png.BestSpeed
+	if err := r.encoder.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// frameImage converts a frame in the given pixel format to RGBA
+func frameImage(pix []byte, stride, height int, format core.PixelFormat) *image.RGBA {
+	if format == core.PixelFormatRGBA8888 {
+		return &image.RGBA{
+			Pix:    pix[:stride*height],
+			Stride: stride,
+			Rect:   image.Rect(0, 0, stride/4, height),
+		}
+	}
+
+	bpp := 4
+	if format == core.PixelFormatRGB565 {
+		bpp = 2
+	}
+	width := stride / bpp
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		src := pix[y*stride:]
+		dst := img.Pix[y*img.Stride:]
+		for x := 0; x < width; x++ {
+			d := dst[x*4 : x*4+4]
+			if format == core.PixelFormatRGB565 {
+				p := uint16(src[x*2]) | uint16(src[x*2+1])<<8
+				r, g, b := uint8(p>>11), uint8(p>>5)&0x3F, uint8(p)&0x1F
+				d[0], d[1], d[2] = r<<3|r>>2, g<<2|g>>4, b<<3|b>>2
+			} else {
+				d[0], d[1], d[2] = src[x*4+2], src[x*4+1], src[x*4]
+			}
+			d[3] = 0xFF
+		}
+	}
+	return img
+}
+
+// fail stops recording after a write error; emulation continues
+func (r *recordingEmulator) fail(err error) {
+	log.Printf("emkiii: recording stopped: %v", err)
+	r.failed = true
+}
+
+// Close finalizes the recording and closes the emulator.
+func (r *recordingEmulator) Close() {
+	if r.wav != nil {
+		if err := r.wav.Close(); err != nil {
+			log.Printf("emkiii: recording: %v", err)
+		}
+		r.wav = nil
+	}
+	if r.frameDir != "" && r.frame > 0 {
+		log.Printf("emkiii: recorded %d frames at %d fps to %s", r.frame, r.GetTiming().FPS, r.frameDir)
+	}
+	r.Emulator.Close()
+}
@@ -0,0 +1,51 @@
+// Command bench runs a ROM headlessly as fast as possible and reports the
+// emulation speed, so renderer and core changes can be compared.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/user-none/emkiii/headless"
+)
+
+func main() {
+	romPath := flag.String("rom", "", "path to ROM file (required)")
+	frames := flag.Int("frames", 3600, "number of frames to run")
+	warmup := flag.Int("warmup", 60, "frames run before timing starts")
+	regionFlag := flag.String("region", "auto", "video standard: auto, ntsc, or pal")
+	videoFilter := flag.String("filter", "none", "video filter: none, scanlines, phosphor, or ntsc")
+	frameskip := flag.Int("frameskip", 0, "frames skipped after each drawn frame")
+	flag.Parse()
+
+	if *romPath == "" {
+		log.Fatal("-rom is required")
+	}
+
+	emu, err := headless.LoadFile(*romPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	emu.SetOption("video_standard", *regionFlag)
+	emu.SetOption("video_filter", *videoFilter)
+	emu.SetOption("frameskip", fmt.Sprint(*frameskip))
+
+	emu.RunFrames(*warmup)
+	emu.Audio()
+
+	// Fetch each frame as a frontend would so filters are included
+	start := time.Now()
+	for i := 0; i < *frames; i++ {
+		emu.RunFrames(1)
+		emu.Frame()
+		emu.Audio()
+	}
+	elapsed := time.Since(start)
+
+	fps := float64(*frames) / elapsed.Seconds()
+	fmt.Printf("%d frames in %v\n", *frames, elapsed.Round(time.Millisecond))
+	fmt.Printf("%.1f fps (%.1fx real time at %d fps)\n", fps, fps/float64(emu.FPS()), emu.FPS())
+	fmt.Printf("%.1f µs/frame\n", float64(elapsed.Microseconds())/float64(*frames))
+}
@@ -4,34 +4,230 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"hash/crc32"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/user-none/eblitui/coreif"
 	"github.com/user-none/eblitui/desktop"
+	"github.com/user-none/eblitui/desktop/storage"
 	"github.com/user-none/emkiii/adapter"
+	"github.com/user-none/emkiii/core"
 )
 
 func main() {
 	romPath := flag.String("rom", "", "path to ROM file (opens UI if not provided)")
 	regionFlag := flag.String("region", "auto", "video standard: auto, ntsc, or pal")
 	cropBorder := flag.Bool("crop-border", false, "crop blank left column when enabled by game")
+	palette := flag.String("palette", "original", "palette: original, contrast, or path to a .pal file")
+	videoFilter := flag.String("filter", "none", "video filter: none, scanlines, phosphor, or ntsc")
+	noSpriteLimit := flag.Bool("no-sprite-limit", false, "draw more than 8 sprites per line to reduce flicker")
+	recordDir := flag.String("record", "", "record frames as PNGs and audio as WAV into this directory (requires -rom or -game)")
+	recordAudio := flag.String("record-audio", "", "record audio only to this WAV file (requires -rom or -game)")
+	romDB := flag.String("romdb", "", "JSON ROM database merged over the built-in mapper and region table")
+	fullscreen := flag.Bool("fullscreen", false, "start the library UI in fullscreen (saved in the desktop config; press F11 in a direct launch)")
+	statePath := flag.String("state", "", "load this save state file at start (requires -rom or -game)")
+	game := flag.String("game", "", "launch the library entry with this CRC32 or title instead of -rom")
+	slot := flag.Int("slot", -1, "load this save state slot (0-9) of the game at start (requires -rom or -game)")
 	flag.Parse()
 
+	if *slot > 9 || (*slot >= 0 && *statePath != "") {
+		log.Fatal("emkiii: -slot must be 0-9 and cannot be combined with -state")
+	}
+
+	if *romDB != "" {
+		n, err := core.LoadROMDatabaseFile(*romDB)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("emkiii: loaded %d ROM database entries from %s", n, *romDB)
+	}
+
+	// The core keeps its current palette when the option is bad, so
+	// report an unreadable palette file here
+	if _, err := core.PaletteByName(*palette); err != nil {
+		log.Fatal(err)
+	}
+
 	factory := &adapter.Factory{}
+	storage.Init(factory.SystemInfo().DataDirName)
+
+	if *game != "" {
+		if *romPath != "" {
+			log.Fatal("emkiii: -game and -rom cannot be combined")
+		}
+		entry, err := findLibraryGame(*game)
+		if err != nil {
+			log.Fatal(err)
+		}
+		*romPath = entry.File
+	}
 
 	if *romPath != "" {
+		var runFactory coreif.CoreFactory = factory
+		if *recordDir != "" || *recordAudio != "" {
+			config := adapter.RecordConfig{AudioPath: *recordAudio}
+			if *recordDir != "" {
+				config.FrameDir = *recordDir
+				if config.AudioPath == "" {
+					config.AudioPath = filepath.Join(*recordDir, "audio.wav")
+				}
+			}
+			runFactory = &adapter.RecordingFactory{Config: config}
+		}
+		launch := &launchFactory{CoreFactory: runFactory, slot: *slot}
+		if *statePath != "" {
+			state, err := os.ReadFile(*statePath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			launch.state = state
+		}
+
 		options := map[string]string{
 			"video_standard": *regionFlag,
+			"palette":        *palette,
+			"video_filter":   *videoFilter,
 		}
 		if *cropBorder {
 			options["crop_border"] = "true"
 		}
-		if err := desktop.RunDirect(factory, *romPath, options, nil); err != nil {
+		if *noSpriteLimit {
+			options["no_sprite_limit"] = "true"
+		}
+		if *fullscreen {
+			log.Printf("emkiii: -fullscreen applies to the library UI; press F11 to go fullscreen in a direct launch")
+		}
+		if err := desktop.RunDirect(launch, *romPath, options, nil); err != nil {
 			log.Fatal(err)
 		}
 		return
 	}
 
+	if *fullscreen {
+		if err := setFullscreenConfig(); err != nil {
+			log.Fatal(err)
+		}
+	}
 	if err := desktop.Run(factory); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// setFullscreenConfig turns on fullscreen in the eblitui desktop config.
+// The library UI restores it on start and saves the window state again on
+// exit, as it does after the user toggles fullscreen.
+func setFullscreenConfig() error {
+	if err := storage.EnsureDirectories(); err != nil {
+		return err
+	}
+	config, err := storage.LoadConfig()
+	if err != nil {
+		return err
+	}
+	config.Window.Fullscreen = true
+	return storage.SaveConfig(config)
+}
+
+// findLibraryGame returns the eblitui library entry for a -game value:
+// a CRC32 in hex, or a title matching either the full No-Intro name or
+// the display name, ignoring case. A display name shared by several
+// entries, such as regional releases, must be given by CRC instead.
+func findLibraryGame(game string) (*storage.GameEntry, error) {
+	lib, err := storage.LoadLibrary()
+	if err != nil {
+		return nil, err
+	}
+	if entry := lib.GetGame(strings.ToLower(game)); entry != nil {
+		return entry, nil
+	}
+
+	var matches []*storage.GameEntry
+	for _, entry := range lib.Games {
+		if strings.EqualFold(entry.Name, game) {
+			return entry, nil
+		}
+		if strings.EqualFold(entry.DisplayName, game) {
+			matches = append(matches, entry)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("emkiii: no library game matches %q", game)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("emkiii: %d library games match %q; use the CRC32", len(matches), game)
+	}
+}
+
+// slotStatePath returns the file the desktop UI saves a slot's state to.
+// Saves are stored per game under the ROM's CRC32, as the library scanner
+// computes it.
+func slotStatePath(rom []byte, slot int) (string, error) {
+	dir, err := storage.GetGameSaveDir(fmt.Sprintf("%08x", crc32.ChecksumIEEE(rom)))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fmt.Sprintf("state-%d.state", slot)), nil
+}
+
+// launchFactory wraps the factory used for a direct launch. It sees the
+// ROM after RunDirect has extracted it from any archive, so the Sega
+// header is checked and a save slot, which is stored under the CRC of
+// the extracted ROM, is read there. When a state is given, emulators it
+// creates resume from it. The state is loaded on Start, after RunDirect
+// has applied the core options, so option changes such as video_standard
+// can't undo it.
+type launchFactory struct {
+	coreif.CoreFactory
+	state []byte
+	slot  int // Save slot to load, or -1
+}
+
+// CreateEmulator checks the ROM header and creates an emulator that loads
+// the state, if any, when started.
+func (f *launchFactory) CreateEmulator(rom []byte) (coreif.Emulator, error) {
+	if err := core.CheckSegaHeader(rom); err != nil {
+		log.Printf("emkiii: %v", err)
+	}
+	if f.slot >= 0 {
+		path, err := slotStatePath(rom, f.slot)
+		if err == nil {
+			f.state, err = os.ReadFile(path)
+		}
+		if err != nil {
+			log.Printf("emkiii: slot %d not loaded: %v", f.slot, err)
+		}
+	}
+	emu, err := f.CoreFactory.CreateEmulator(rom)
+	if err != nil || f.state == nil {
+		return emu, err
+	}
+	inner, ok := emu.(adapter.Emulator)
+	if !ok {
+		emu.Close()
+		return nil, fmt.Errorf("unsupported emulator type %T", emu)
+	}
+	return &stateEmulator{Emulator: inner, state: f.state}, nil
+}
+
+// stateEmulator loads a save state on Start. It embeds adapter.Emulator
+// so save states, SRAM persistence and memory access stay available to
+// the frontend.
+type stateEmulator struct {
+	adapter.Emulator
+	state []byte
+}
+
+// Start starts the emulator and restores the save state. A state that
+// fails to load is logged and the game starts from power-on.
+func (e *stateEmulator) Start() {
+	e.Emulator.Start()
+	if err := e.Deserialize(e.state); err != nil {
+		log.Printf("emkiii: state not loaded: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+// Command detcheck runs two emulators in lockstep on the same ROM and
+// input and compares them every few frames, reporting the first frame at
+// which their state, picture or sound diverges. Netplay and run-ahead
+// both depend on the emulator being deterministic.
+//
+// With -reload the second emulator is saved and restored from its own
+// state at every check, so anything the save state misses shows up as a
+// divergence.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"slices"
+
+	"github.com/user-none/emkiii/core"
+	"github.com/user-none/emkiii/headless"
+)
+
+// inputButtons are chosen from for the random input
+var inputButtons = []headless.Buttons{
+	headless.Up, headless.Down, headless.Left, headless.Right,
+	headless.Button1, headless.Button2,
+}
+
+func main() {
+	romPath := flag.String("rom", "", "path to ROM file (required)")
+	frames := flag.Int("frames", 3600, "number of frames to run")
+	every := flag.Int("every", 60, "compare the emulators every this many frames")
+	seed := flag.Int64("seed", 1, "seed for the random input")
+	pause := flag.Bool("pause", false, "include presses of the console pause button in the input")
+	reload := flag.Bool("reload", false, "save and reload the second emulator's state at every check")
+	regionFlag := flag.String("region", "auto", "video standard: auto, ntsc, or pal")
+	flag.Parse()
+
+	if *romPath == "" {
+		log.Fatal("-rom is required")
+	}
+	if *every < 1 {
+		log.Fatal("-every must be at least 1")
+	}
+
+	rom, err := os.ReadFile(*romPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var emus [2]*headless.Emulator
+	for i := range emus {
+		emus[i], err = headless.Load(rom)
+		if err != nil {
+			log.Fatal(err)
+		}
+		emus[i].SetOption("video_standard", *regionFlag)
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	for frame := 0; frame < *frames; frame += *every {
+		n := min(*every, *frames-frame)
+		for i := 0; i < n; i++ {
+			// Hold each random input for a few frames, as a player would
+			if (frame+i)%8 == 0 {
+				input := randomInput(rng, *pause)
+				for _, emu := range emus {
+					emu.SetInput(0, input)
+				}
+			}
+			for _, emu := range emus {
+				emu.RunFrames(1)
+			}
+		}
+
+		if err := compare(emus, frame+n); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if *reload {
+			state, err := emus[1].SaveState()
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := emus[1].LoadState(state); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+	fmt.Printf("deterministic over %d frames\n", *frames)
+}
+
+// randomInput returns a random button combination
+func randomInput(rng *rand.Rand, pause bool) headless.Buttons {
+	var input headless.Buttons
+	for _, b := range inputButtons {
+		if rng.Intn(4) == 0 {
+			input |= b
+		}
+	}
+	if pause && rng.Intn(32) == 0 {
+		input |= headless.Pause
+	}
+	return input
+}
+
+// compare returns an error describing how the emulators differ after
+// frame, or nil if they match
+func compare(emus [2]*headless.Emulator, frame int) error {
+	var states [2][]byte
+	for i, emu := range emus {
+		state, err := emu.SaveState()
+		if err != nil {
+			return err
+		}
+		states[i] = state
+	}
+
+	diffs, err := core.DiffStates(states[0], states[1])
+	if err != nil {
+		return err
+	}
+	if len(diffs) > 0 {
+		msg := fmt.Sprintf("state diverged by frame %d:", frame)
+		for _, d := range diffs {
+			msg += fmt.Sprintf("\n  %s %s (%d bytes differ)", d.Section, d.Field, len(d.Differing()))
+		}
+		return fmt.Errorf("%s", msg)
+	}
+
+	pixels0, w0, h0 := emus[0].Frame()
+	pixels1, w1, h1 := emus[1].Frame()
+	if w0 != w1 || h0 != h1 || !bytes.Equal(pixels0, pixels1) {
+		return fmt.Errorf("picture diverged by frame %d", frame)
+	}
+
+	if !slices.Equal(emus[0].Audio(), emus[1].Audio()) {
+		return fmt.Errorf("audio diverged by frame %d", frame)
+	}
+	return nil
+}
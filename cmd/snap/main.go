@@ -0,0 +1,167 @@
+// Command snap runs ROMs headlessly and saves a PNG of the screen after a
+// number of frames, for generating library thumbnails for games with no
+// box art.
+//
+//	snap -frames 900 -dir thumbs homebrew/*.sms
+//
+// An input script can drive the game past title screens. Each line holds a
+// frame number and the buttons held from that frame on, joined with "+"
+// (up, down, left, right, 1, 2, pause, or none):
+//
+//	# start the game, then hold right
+//	120 pause
+//	121 none
+//	300 1+right
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/user-none/emkiii/headless"
+)
+
+// inputEvent changes the held buttons at a frame
+type inputEvent struct {
+	frame   int
+	buttons headless.Buttons
+}
+
+var buttonNames = map[string]headless.Buttons{
+	"up":    headless.Up,
+	"down":  headless.Down,
+	"left":  headless.Left,
+	"right": headless.Right,
+	"1":     headless.Button1,
+	"2":     headless.Button2,
+	"pause": headless.Pause,
+	"none":  0,
+}
+
+func main() {
+	frames := flag.Int("frames", 600, "frames to run before the screenshot")
+	outDir := flag.String("dir", ".", "directory PNGs are written to, named after each ROM")
+	inputPath := flag.String("input", "", "input script applied to player 1")
+	regionFlag := flag.String("region", "auto", "video standard: auto, ntsc, or pal")
+	videoFilter := flag.String("filter", "none", "video filter: none, scanlines, phosphor, or ntsc")
+	cropBorder := flag.Bool("crop-border", false, "crop blank left column when enabled by game")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: snap [flags] rom...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var script []inputEvent
+	if *inputPath != "" {
+		var err error
+		script, err = loadInputScript(*inputPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	options := map[string]string{
+		"video_standard": *regionFlag,
+		"video_filter":   *videoFilter,
+		"crop_border":    strconv.FormatBool(*cropBorder),
+	}
+
+	failed := false
+	for _, romPath := range flag.Args() {
+		out := filepath.Join(*outDir, strings.TrimSuffix(filepath.Base(romPath), filepath.Ext(romPath))+".png")
+		if err := snap(romPath, out, *frames, options, script); err != nil {
+			log.Printf("%s: %v", romPath, err)
+			failed = true
+			continue
+		}
+		fmt.Println(out)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// snap runs one ROM and writes its screen after frames frames to out
+func snap(romPath, out string, frames int, options map[string]string, script []inputEvent) error {
+	emu, err := headless.LoadFile(romPath)
+	if err != nil {
+		return err
+	}
+	for key, value := range options {
+		emu.SetOption(key, value)
+	}
+
+	next := 0
+	for frame := 0; frame < frames; frame++ {
+		for next < len(script) && script[next].frame <= frame {
+			emu.SetInput(0, script[next].buttons)
+			next++
+		}
+		emu.RunFrames(1)
+		emu.Audio()
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, emu.Screenshot()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadInputScript parses an input script, sorted by frame
+func loadInputScript(path string) ([]inputEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var script []inputEvent
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: want \"<frame> <buttons>\"", path, line)
+		}
+		frame, err := strconv.Atoi(fields[0])
+		if err != nil || frame < 0 {
+			return nil, fmt.Errorf("%s:%d: bad frame %q", path, line, fields[0])
+		}
+		var buttons headless.Buttons
+		for _, name := range strings.Split(strings.ToLower(fields[1]), "+") {
+			b, ok := buttonNames[name]
+			if !ok {
+				return nil, fmt.Errorf("%s:%d: unknown button %q", path, line, name)
+			}
+			buttons |= b
+		}
+		script = append(script, inputEvent{frame: frame, buttons: buttons})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(script, func(i, j int) bool { return script[i].frame < script[j].frame })
+	return script, nil
+}
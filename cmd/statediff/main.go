@@ -0,0 +1,72 @@
+// Command statediff compares two save states for the same ROM and lists
+// the CPU registers, RAM bytes, VDP registers and other fields that
+// differ, for tracking down desyncs and serialization bugs.
+//
+// Like diff, it exits with status 0 when the states match, 1 when they
+// differ and 2 on error.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/user-none/emkiii/core"
+)
+
+// smallField is the largest field printed whole instead of byte by byte
+const smallField = 8
+
+func main() {
+	maxBytes := flag.Int("max", 16, "differing bytes listed per large field (0 for all)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: statediff [flags] a.state b.state\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	a, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Print(err)
+		os.Exit(2)
+	}
+	b, err := os.ReadFile(flag.Arg(1))
+	if err != nil {
+		log.Print(err)
+		os.Exit(2)
+	}
+
+	diffs, err := core.DiffStates(a, b)
+	if err != nil {
+		log.Print(err)
+		os.Exit(2)
+	}
+	if len(diffs) == 0 {
+		fmt.Println("states match")
+		return
+	}
+
+	for _, d := range diffs {
+		if len(d.A) <= smallField {
+			fmt.Printf("%s %s: % X -> % X\n", d.Section, d.Field, d.A, d.B)
+			continue
+		}
+
+		offsets := d.Differing()
+		fmt.Printf("%s %s: %d of %d bytes differ\n", d.Section, d.Field, len(offsets), len(d.A))
+		for i, off := range offsets {
+			if *maxBytes > 0 && i == *maxBytes {
+				fmt.Printf("  ... %d more\n", len(offsets)-i)
+				break
+			}
+			fmt.Printf("  +%04X: %02X -> %02X\n", off, d.A[off], d.B[off])
+		}
+	}
+	os.Exit(1)
+}
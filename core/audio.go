@@ -0,0 +1,179 @@
+package core
+
+import (
+	"math"
+
+	"github.com/user-none/go-chip-sn76489"
+)
+
+// PSG channels for SetAudioChannelVolume
+const (
+	AudioChannelTone0 = iota
+	AudioChannelTone1
+	AudioChannelTone2
+	AudioChannelNoise
+	audioChannelCount
+)
+
+// audioOptionChannels maps the per-channel volume option keys to channels
+var audioOptionChannels = map[string]int{
+	"volume_tone0": AudioChannelTone0,
+	"volume_tone1": AudioChannelTone1,
+	"volume_tone2": AudioChannelTone2,
+	"volume_noise": AudioChannelNoise,
+}
+
+// lowPassCutoffHz approximates the RC filter on the console's audio
+// output, which rounds off the edges of the PSG's square waves.
+const lowPassCutoffHz = 3400
+
+// psgMixer sits between the I/O port and the PSG and applies per-channel
+// volume by adding attenuation to the game's volume writes. The chip mixes
+// its channels internally, so attenuation is the only control available
+// and volume is quantized to the chip's 2 dB steps.
+type psgMixer struct {
+	psg       *sn76489.SN76489
+	requested [audioChannelCount]uint8 // Attenuation last written by the game
+	extra     [audioChannelCount]uint8 // Added attenuation (15 mutes the channel)
+	latch     uint8                    // Last latch byte written by the game
+	relatch   bool                     // The chip is latched to a volume register, not the game's noise latch
+}
+
+// newPSGMixer creates a mixer for a PSG in its power-on state, with all
+// channels silent and tone 0 latched
+func newPSGMixer(psg *sn76489.SN76489) psgMixer {
+	return psgMixer{
+		psg:       psg,
+		requested: [audioChannelCount]uint8{0x0F, 0x0F, 0x0F, 0x0F},
+		latch:     0x80,
+	}
+}
+
+// attenuation returns the attenuation sent to the chip for a channel
+func (m *psgMixer) attenuation(ch uint8) uint8 {
+	a := m.requested[ch] + m.extra[ch]
+	if a > 0x0F {
+		a = 0x0F
+	}
+	return a
+}
+
+// write forwards a PSG port write, adjusting volume values
+func (m *psgMixer) write(value uint8) {
+	if value&0x80 != 0 {
+		m.latch = value
+		m.relatch = false
+	} else if m.relatch {
+		// The game's data byte was meant for the noise register. Sent as
+		// a latch byte it has the same effect and latches the chip to
+		// noise again.
+		value = m.latch&0xF0 | value&0x0F
+		m.relatch = false
+	}
+	// Latch bytes and data bytes to a latched volume register both carry
+	// the attenuation in the low 4 bits
+	if m.latch&0x10 != 0 {
+		ch := (m.latch >> 5) & 0x03
+		m.requested[ch] = value & 0x0F
+		value = value&0xF0 | m.attenuation(ch)
+	}
+	m.psg.Write(value)
+}
+
+// setVolume sets a channel's added attenuation and applies it to the chip
+// immediately. The game's latched register is restored afterwards so a
+// following data byte still reaches the register the game selected.
+// Restoring a noise latch would rewrite the noise register and reset its
+// shift register, changing the game's audio, so that latch is instead
+// restored by write when the next data byte arrives.
+func (m *psgMixer) setVolume(ch uint8, extra uint8) {
+	if m.extra[ch] == extra {
+		return
+	}
+	m.extra[ch] = extra
+	if m.psg == nil {
+		return
+	}
+
+	m.psg.Write(0x90 | ch<<5 | m.attenuation(ch))
+
+	latchCh := (m.latch >> 5) & 0x03
+	switch {
+	case m.latch&0x10 != 0:
+		m.psg.Write(m.latch&0xF0 | m.attenuation(latchCh))
+	case latchCh == AudioChannelNoise:
+		m.relatch = true
+	default:
+		m.psg.Write(m.latch&0xF0 | uint8(m.psg.GetToneReg(int(latchCh))&0x0F))
+	}
+}
+
+// resync rebuilds the game's requested attenuation from the chip after its
+// state was replaced by a save state load
+func (m *psgMixer) resync() {
+	m.latch = 0x80
+	m.relatch = false
+	for ch := range m.requested {
+		v := m.psg.GetVolume(ch)
+		switch {
+		case m.extra[ch] == 0x0F:
+			m.requested[ch] = 0x0F
+		case v >= m.extra[ch]:
+			m.requested[ch] = v - m.extra[ch]
+		default:
+			m.requested[ch] = 0
+		}
+	}
+}
+
+// volumeToAttenuation converts a 0-100 volume percentage to 2 dB
+// attenuation steps, rounding to the nearest step. 0 mutes the channel.
+func volumeToAttenuation(percent int) uint8 {
+	if percent <= 0 {
+		return 0x0F
+	}
+	if percent >= 100 {
+		return 0
+	}
+	steps := math.Round(-10 * math.Log10(float64(percent)/100))
+	if steps > 0x0F {
+		return 0x0F
+	}
+	return uint8(steps)
+}
+
+// audioLowPass is a one-pole RC low-pass filter
+type audioLowPass struct {
+	enabled bool
+	alpha   float32
+	state   float32
+}
+
+// newAudioLowPass creates a filter for the given sample rate
+func newAudioLowPass(sampleRate int) audioLowPass {
+	rc := 1 / (2 * math.Pi * lowPassCutoffHz)
+	dt := 1 / float64(sampleRate)
+	return audioLowPass{alpha: float32(dt / (rc + dt))}
+}
+
+// process filters one sample
+func (f *audioLowPass) process(x float32) float32 {
+	f.state += f.alpha * (x - f.state)
+	return f.state
+}
+
+// SetAudioChannelVolume sets the volume of one PSG channel as a percentage
+// (0 mutes, 100 is unchanged). Useful for isolating parts when listening
+// to music or debugging sound code.
+func (e *Emulator) SetAudioChannelVolume(channel int, percent int) {
+	if channel < 0 || channel >= audioChannelCount {
+		return
+	}
+	e.io.mixer.setVolume(uint8(channel), volumeToAttenuation(percent))
+}
+
+// SetAudioLowPass enables the output low-pass filter
+func (e *Emulator) SetAudioLowPass(enabled bool) {
+	e.lowPass.enabled = enabled
+	e.lowPass.state = 0
+}
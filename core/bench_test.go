@@ -0,0 +1,131 @@
+package core
+
+import "testing"
+
+// benchEmulator returns an emulator showing the golden test scene with
+// the display enabled, so frames exercise background and sprite rendering
+func benchEmulator() *Emulator {
+	e := createTestEmulator()
+	goldenScene(e.vdp, 0x26, 0x42)
+	return e
+}
+
+// BenchmarkEmulator_RunFrame measures a full frame: CPU, VDP and PSG
+func BenchmarkEmulator_RunFrame(b *testing.B) {
+	e := benchEmulator()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.RunFrame()
+	}
+}
+
+// BenchmarkEmulator_RunFrameSkipped measures a frame dropped by frameskip
+func BenchmarkEmulator_RunFrameSkipped(b *testing.B) {
+	e := benchEmulator()
+	e.SetFrameskip(1)
+	e.RunFrame()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.skipCounter = 1
+		e.RunFrame()
+	}
+}
+
+// BenchmarkVDP_RenderScanline measures one active display line
+func BenchmarkVDP_RenderScanline(b *testing.B) {
+	vdp := NewVDP()
+	goldenScene(vdp, 0x26, 0x42)
+	vdp.LatchVScrollForFrame()
+	vdp.SetVCounter(100)
+	vdp.LatchCRAM()
+	vdp.LatchPerLineRegisters()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vdp.RenderScanline()
+	}
+}
+
+// BenchmarkSerialize measures saving a state into a reused buffer, as
+// rewind and run-ahead do every frame
+func BenchmarkSerialize(b *testing.B) {
+	e := benchEmulator()
+	buf := make([]byte, SerializeSize())
+
+	b.SetBytes(int64(len(buf)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := e.SerializeInto(buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDeserialize measures loading a state
+func BenchmarkDeserialize(b *testing.B) {
+	e := benchEmulator()
+	state, err := e.Serialize()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(state)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := e.Deserialize(state); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkVideoFilter measures each post-processing filter on a full
+// frame, including the crop and filter chain overhead of GetFramebuffer
+func BenchmarkVideoFilter(b *testing.B) {
+	for _, name := range []string{"none", FilterScanlines, FilterPhosphor, FilterNTSC, "blend"} {
+		b.Run(name, func(b *testing.B) {
+			e := benchEmulator()
+			if name == "blend" {
+				e.SetOption("frame_blend", "true")
+			} else {
+				e.SetOption("video_filter", name)
+			}
+			e.RunFrame()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				e.filterValid = false
+				e.GetFramebuffer()
+			}
+		})
+	}
+}
+
+// TestRunFrame_AllocBudget tests that steady-state frames do not allocate,
+// including with filters, frame blending and overscan. Timing budgets
+// vary by machine and are left to the benchmarks; allocations do not.
+func TestRunFrame_AllocBudget(t *testing.T) {
+	options := [][2]string{
+		{"video_filter", "none"},
+		{"video_filter", FilterNTSC},
+		{"frame_blend", "true"},
+		{"overscan", "true"},
+		{"crop_border", "true"},
+	}
+	for _, opt := range options {
+		e := benchEmulator()
+		e.SetOption(opt[0], opt[1])
+		e.RunFrame()
+		e.GetFramebuffer()
+
+		allocs := testing.AllocsPerRun(5, func() {
+			e.RunFrame()
+			e.GetFramebuffer()
+			e.GetAudioSamples()
+		})
+		if allocs != 0 {
+			t.Errorf("%s=%s: RunFrame allocated %.0f times per frame", opt[0], opt[1], allocs)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/user-none/go-chip-z80"
+)
+
+// DefaultCPUCore is the name of the Z80 core used unless another is selected
+const DefaultCPUCore = "go-chip-z80"
+
+// CPU is the Z80 core driven by the emulator.
+//
+// Serialize and Deserialize must use go-chip-z80's layout (z80.SerializeSize
+// bytes) so save states and state diffs work with every core, and so a
+// running game can be moved from one core to another.
+type CPU interface {
+	Step() int
+	StepCycles(budget int) int
+	Cycles() uint64
+	INT(assert bool, data uint8)
+	NMI()
+	Registers() z80.Registers
+	SetState(regs z80.Registers)
+	Serialize(buf []byte) error
+	Deserialize(buf []byte) error
+}
+
+// CPUFactory creates a CPU connected to the system bus
+type CPUFactory func(bus z80.Bus) CPU
+
+var (
+	cpuCoresMu sync.RWMutex
+	cpuCores   = map[string]CPUFactory{
+		DefaultCPUCore: func(bus z80.Bus) CPU { return z80.New(bus) },
+	}
+)
+
+// RegisterCPUCore makes a Z80 core selectable by name with the "cpu_core"
+// option. Registering an existing name replaces it.
+func RegisterCPUCore(name string, factory CPUFactory) {
+	cpuCoresMu.Lock()
+	defer cpuCoresMu.Unlock()
+	cpuCores[name] = factory
+}
+
+// cpuCoreFactory returns the factory registered under name.
+func cpuCoreFactory(name string) (CPUFactory, bool) {
+	cpuCoresMu.RLock()
+	defer cpuCoresMu.RUnlock()
+	factory, ok := cpuCores[name]
+	return factory, ok
+}
+
+// CPUCores returns the names of the registered Z80 cores in sorted order.
+func CPUCores() []string {
+	cpuCoresMu.RLock()
+	defer cpuCoresMu.RUnlock()
+	names := make([]string, 0, len(cpuCores))
+	for name := range cpuCores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetCPUCore switches to the named Z80 core. The running CPU state is
+// carried over, so this is safe to call mid-game.
+func (e *Emulator) SetCPUCore(name string) error {
+	factory, ok := cpuCoreFactory(name)
+	if !ok {
+		return fmt.Errorf("unknown CPU core %q", name)
+	}
+	if name == e.cpuCore {
+		return nil
+	}
+
+	var state [z80.SerializeSize]byte
+	if err := e.cpu.Serialize(state[:]); err != nil {
+		return err
+	}
+	cpu := factory(e.bus)
+	if err := cpu.Deserialize(state[:]); err != nil {
+		return err
+	}
+	e.cpu = cpu
+	e.cpuCore = name
+	return nil
+}
+
+// CPUCore returns the name of the Z80 core in use.
+func (e *Emulator) CPUCore() string {
+	return e.cpuCore
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/user-none/go-chip-z80"
+)
+
+// countingCPU wraps the default core and counts the instructions it runs
+type countingCPU struct {
+	*z80.CPU
+	steps int
+}
+
+func (c *countingCPU) StepCycles(budget int) int {
+	c.steps++
+	return c.CPU.StepCycles(budget)
+}
+
+// TestEmulator_SetCPUCore tests switching cores mid-game keeps CPU state
+func TestEmulator_SetCPUCore(t *testing.T) {
+	name := t.Name()
+	var counting *countingCPU
+	RegisterCPUCore(name, func(bus z80.Bus) CPU {
+		counting = &countingCPU{CPU: z80.New(bus)}
+		return counting
+	})
+	t.Cleanup(func() {
+		cpuCoresMu.Lock()
+		delete(cpuCores, name)
+		cpuCoresMu.Unlock()
+	})
+
+	e, _ := NewEmulator(createTestROM(4))
+	if e.CPUCore() != DefaultCPUCore {
+		t.Fatalf("Expected core %q, got %q", DefaultCPUCore, e.CPUCore())
+	}
+	e.RunFrame()
+	before := e.cpu.Registers()
+	cycles := e.cpu.Cycles()
+
+	e.SetOption("cpu_core", name)
+	if e.CPUCore() != name || counting == nil {
+		t.Fatalf("Expected core %q, got %q", name, e.CPUCore())
+	}
+	if got := e.cpu.Registers(); got != before {
+		t.Errorf("Registers not carried over: expected %+v, got %+v", before, got)
+	}
+	if got := e.cpu.Cycles(); got != cycles {
+		t.Errorf("Cycles not carried over: expected %d, got %d", cycles, got)
+	}
+
+	e.RunFrame()
+	if counting.steps == 0 {
+		t.Error("Selected core did not run")
+	}
+}
+
+// TestEmulator_SetCPUCoreUnknown tests an unknown core is rejected
+func TestEmulator_SetCPUCoreUnknown(t *testing.T) {
+	e, _ := NewEmulator(createTestROM(4))
+	if err := e.SetCPUCore("missing"); err == nil {
+		t.Error("Expected error for unknown core")
+	}
+	if e.CPUCore() != DefaultCPUCore {
+		t.Errorf("Core changed after error: %q", e.CPUCore())
+	}
+}
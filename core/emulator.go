@@ -4,7 +4,9 @@ import (
 	"encoding/binary"
 	"errors"
 	"hash/crc32"
-	"strings"
+	"image"
+	"io"
+	"strconv"
 
 	"github.com/user-none/eblitui/coreif"
 	"github.com/user-none/go-chip-sn76489"
@@ -13,41 +15,80 @@ import (
 
 const (
 	ScreenWidth     = 256
-	MaxScreenHeight = 224
-	sampleRate      = 48000
+	MaxScreenHeight = 240
+	SampleRate      = 48000 // Audio output rate in Hz
 )
 
 // Save state format constants
 const (
-	stateVersion    = 1
+	stateVersion    = 2
 	stateMagic      = "eMkIIISState"
 	stateHeaderSize = 22 // magic(12) + version(2) + romCRC(4) + dataCRC(4)
 )
 
 // Emulator contains the emulator core components.
 type Emulator struct {
-	cpu                 *z80.CPU
+	cpu                 CPU
+	bus                 *SMSBus
 	mem                 *Memory
 	vdp                 *VDP
 	psg                 *sn76489.SN76489
 	io                  *SMSIO
 	cyclesPerScanlineFP int // Fixed-point (16 fractional bits) for accurate timing
 
+	// Name of the Z80 core in use (see cpu.go)
+	cpuCore string
+
 	// Video standard timing
 	videoStd  VideoStandard
 	timing    VideoTiming
 	scanlines int
 
-	// Input edge detection for pause button
-	prevButtons [2]uint32
+	// Pause button level, for NMI edge detection (see SetPauseButton)
+	pauseHeld bool
+
+	// Notifications for the frontend (see events.go)
+	events      FrameEvent
+	inputEvents FrameEvent
+
+	// Battery save write tracking (see sram.go)
+	sramDirty       bool
+	sramQuietFrames int
+
+	// Exchange buttons 1 and 2 on both controllers
+	swapButtons bool
+
+	// Frameskip: render one frame, then skip this many
+	frameskip   int
+	skipCounter int
+	skipRender  bool // Current frame is not drawn
+
+	// Reused by ReadRegion so per-frame memory syncs don't allocate
+	regionBuffers struct {
+		ram     [0x2000]byte
+		cartRAM [0x8000]byte
+	}
 
 	// Crop border support
 	cropBorder bool
 	cropBuffer []byte
 
+	// Overscan support: full frame including backdrop-colored borders
+	overscan       bool
+	overscanBuffer *image.RGBA
+
+	// Video post-processing chain (see filter.go)
+	videoFilterName string // video_filter option
+	frameBlend      bool   // frame_blend option
+	videoFilters    []VideoFilter
+	filterBuffers   [2][]byte
+	filterOut       int  // Index of the buffer holding the filtered frame
+	filterValid     bool // Filtered frame is current for this frame
+
 	// Pre-allocated audio buffers to avoid per-frame allocations
 	frameSamples []float32 // Collects float32 samples during scanline emulation
 	audioBuffer  []int16   // Final int16 stereo output for external consumption
+	lowPass      audioLowPass
 }
 
 // NewEmulator creates and initializes the emulator components.
@@ -62,8 +103,8 @@ func NewEmulator(rom []byte) (Emulator, error) {
 	timing := GetVideoTiming(videoStd)
 	vdp.SetTotalScanlines(timing.Scanlines)
 
-	samplesPerFrame := sampleRate / timing.FPS
-	psg := sn76489.New(timing.CPUClockHz, sampleRate, samplesPerFrame*2, sn76489.Sega)
+	samplesPerFrame := SampleRate / timing.FPS
+	psg := sn76489.New(timing.CPUClockHz, SampleRate, samplesPerFrame*2, sn76489.Sega)
 
 	nationality := DetectNationalityFromROM(rom)
 	io := NewSMSIO(vdp, psg, nationality)
@@ -74,18 +115,22 @@ func NewEmulator(rom []byte) (Emulator, error) {
 
 	return Emulator{
 		cpu:                 cpu,
+		bus:                 bus,
+		cpuCore:             DefaultCPUCore,
+		cyclesPerScanlineFP: cyclesPerScanlineFP,
 		mem:                 mem,
 		vdp:                 vdp,
 		psg:                 psg,
 		io:                  io,
-		cyclesPerScanlineFP: cyclesPerScanlineFP,
 		videoStd:            videoStd,
 		timing:              timing,
 		scanlines:           timing.Scanlines,
 		cropBuffer:          make([]byte, (ScreenWidth-8)*MaxScreenHeight*4),
+		overscanBuffer:      newOverscanBuffer(),
 		// Pre-allocate audio buffers: ~800 samples/frame at 48kHz/60fps
 		frameSamples: make([]float32, 0, 1024),
 		audioBuffer:  make([]int16, 0, 2048),
+		lowPass:      newAudioLowPass(SampleRate),
 	}, nil
 }
 
@@ -152,6 +197,7 @@ func (e *Emulator) runScanlines() {
 			}
 
 			e.vdp.SetHCounter(GetHCounterForCycle(consumed))
+			e.vdp.SetLineCycle(consumed)
 			consumed += e.cpu.StepCycles(scanlineBudget - consumed)
 
 			// Check if VDP register write requires interrupt state update.
@@ -180,7 +226,15 @@ func (e *Emulator) runScanlines() {
 		}
 
 		if i < activeHeight {
-			e.vdp.RenderScanline()
+			if e.skipRender {
+				e.vdp.SkipScanline()
+			} else {
+				e.vdp.RenderScanline()
+			}
+		}
+
+		if e.overscanActive() && !e.skipRender {
+			e.renderOverscanLine(i, activeHeight)
 		}
 
 		e.psg.GenerateSamples(scanlineBudget)
@@ -200,56 +254,105 @@ func (e *Emulator) SetInput(player int, buttons uint32) {
 	right := buttons&(1<<coreif.ButtonRight) != 0
 	btn1 := buttons&(1<<4) != 0
 	btn2 := buttons&(1<<5) != 0
+	if e.swapButtons {
+		btn1, btn2 = btn2, btn1
+	}
 
 	switch player {
 	case 0:
 		e.io.Input.SetP1(up, down, left, right, btn1, btn2)
-		// Edge detect pause (bit 7): trigger NMI on press (0->1)
-		pauseNow := buttons&(1<<7) != 0
-		pausePrev := e.prevButtons[0]&(1<<7) != 0
-		if pauseNow && !pausePrev {
-			e.cpu.NMI()
-		}
+		// Pause is on the console and is reported as player 1 bit 7
+		e.SetPauseButton(buttons&(1<<7) != 0)
 	case 1:
 		e.io.Input.SetP2(up, down, left, right, btn1, btn2)
 	}
+}
 
-	if player < 2 {
-		e.prevButtons[player] = buttons
+// SetPauseButton sets whether the console pause button is held. An NMI
+// is raised only when the button goes from released to pressed, so
+// frontends can pass the raw button state every frame.
+func (e *Emulator) SetPauseButton(pressed bool) {
+	if pressed && !e.pauseHeld {
+		e.cpu.NMI()
+		e.inputEvents |= EventPause
 	}
+	e.pauseHeld = pressed
 }
 
-// GetFramebuffer returns raw RGBA pixel data for current frame.
-// When crop border is enabled and the VDP has left column blank active,
-// the left 8 pixels are stripped from each row.
+// GetFramebuffer returns raw pixel data for current frame, RGBA unless
+// another format was selected with SetPixelFormat. When overscan is
+// enabled the frame includes the border area around the active display.
+// Otherwise, when crop border is enabled and the VDP has left column
+// blank active, the left 8 pixels are stripped from each row.
+// Any video filters are applied to the result (RGBA only).
 func (e *Emulator) GetFramebuffer() []byte {
+	frame := e.frame()
+	if len(e.videoFilters) == 0 || !e.vdp.rgbaOutput() {
+		return frame
+	}
+	return e.applyVideoFilters(frame, e.GetFramebufferStride(), e.GetActiveHeight())
+}
+
+// frame returns the unfiltered frame selected by the overscan and crop options
+func (e *Emulator) frame() []byte {
+	if e.overscanActive() {
+		return e.overscanBuffer.Pix[:e.overscanBuffer.Stride*overscanHeight(e.videoStd)]
+	}
+	src := e.vdp.PixelBuffer()
 	if e.cropBorder && e.vdp.LeftColumnBlankEnabled() {
-		srcStride := e.vdp.framebuffer.Stride
-		dstStride := (ScreenWidth - 8) * 4
+		bpp := e.vdp.bytesPerPixel()
+		srcStride := ScreenWidth * bpp
+		dstStride := (ScreenWidth - 8) * bpp
 		activeHeight := e.vdp.ActiveHeight()
 		for y := 0; y < activeHeight; y++ {
-			srcOff := y*srcStride + 8*4 // skip 8 pixels
+			srcOff := y*srcStride + 8*bpp // skip 8 pixels
 			dstOff := y * dstStride
-			copy(e.cropBuffer[dstOff:dstOff+dstStride], e.vdp.framebuffer.Pix[srcOff:srcOff+dstStride])
+			copy(e.cropBuffer[dstOff:dstOff+dstStride], src[srcOff:srcOff+dstStride])
 		}
 		return e.cropBuffer[:dstStride*activeHeight]
 	}
-	return e.vdp.framebuffer.Pix
+	return src
+}
+
+// overscanActive reports whether frames include the border area.
+// Overscan is composed in RGBA and is bypassed for other pixel formats.
+func (e *Emulator) overscanActive() bool {
+	return e.overscan && e.vdp.rgbaOutput()
 }
 
 // GetFramebufferStride returns the stride (bytes per row) of the framebuffer.
 func (e *Emulator) GetFramebufferStride() int {
+	if e.overscanActive() {
+		return e.overscanBuffer.Stride
+	}
 	if e.cropBorder && e.vdp.LeftColumnBlankEnabled() {
-		return (ScreenWidth - 8) * 4
+		return (ScreenWidth - 8) * e.vdp.bytesPerPixel()
 	}
-	return e.vdp.framebuffer.Stride
+	return ScreenWidth * e.vdp.bytesPerPixel()
 }
 
-// GetActiveHeight returns the current active display height (192 or 224)
+// GetActiveHeight returns the current active display height (192, 224 or 240).
+// With overscan enabled it returns the full bordered frame height instead.
 func (e *Emulator) GetActiveHeight() int {
+	if e.overscanActive() {
+		return overscanHeight(e.videoStd)
+	}
 	return e.vdp.ActiveHeight()
 }
 
+// Unsupported240Line reports whether the game selected 240-line mode on
+// NTSC, which the VDP cannot display and shows as 192 lines instead. A
+// frontend can use it to suggest switching the video standard to PAL.
+func (e *Emulator) Unsupported240Line() bool {
+	return e.videoStd == VideoNTSC && e.vdp.Requested240LineMode()
+}
+
+// VDP returns the video display processor for debug inspection
+// (tile, name table, palette and sprite viewers).
+func (e *Emulator) VDP() *VDP {
+	return e.vdp
+}
+
 // GetTiming returns FPS and scanline count for the current video standard.
 func (e *Emulator) GetTiming() coreif.Timing {
 	return coreif.Timing{
@@ -275,14 +378,55 @@ func (e *Emulator) SetOption(key string, value string) {
 	switch key {
 	case "crop_border":
 		e.cropBorder = value == "true"
+		e.filterValid = false
+	case "overscan":
+		e.overscan = value == "true"
+		e.filterValid = false
+	case "palette":
+		// A palette file that can't be loaded keeps the current palette
+		if p, err := PaletteByName(value); err == nil {
+			e.SetPalette(p)
+		}
+	case "video_filter":
+		e.videoFilterName = value
+		e.updateVideoFilters()
+	case "frame_blend":
+		e.frameBlend = value == "true"
+		e.updateVideoFilters()
+	case "no_sprite_limit":
+		e.vdp.SetNoSpriteLimit(value == "true")
+	case "midline_writes":
+		e.vdp.SetMidLineWrites(value == "true")
+	case "blanked_sprite_collision":
+		e.vdp.SetBlankedCollision(value == "true")
+	case "vram_access_timing":
+		e.vdp.SetAccessTiming(value == "true")
+	case "cpu_core":
+		// Unknown cores keep the current one
+		_ = e.SetCPUCore(value)
+	case "frameskip":
+		if n, err := strconv.Atoi(value); err == nil {
+			e.SetFrameskip(n)
+		}
+	case "swap_buttons":
+		e.swapButtons = value == "true"
+	case "audio_lowpass":
+		e.SetAudioLowPass(value == "true")
+	case "volume_tone0", "volume_tone1", "volume_tone2", "volume_noise":
+		if percent, err := strconv.Atoi(value); err == nil {
+			e.SetAudioChannelVolume(audioOptionChannels[key], percent)
+		}
+	case "mapper":
+		mapper, err := ParseMapper(value)
+		if err != nil {
+			mapper = detectMapper(e.mem.rom)
+		}
+		if mapper != e.mem.Mapper() {
+			e.mem.SetMapper(mapper)
+		}
 	case "video_standard":
-		var v VideoStandard
-		switch strings.ToLower(value) {
-		case "ntsc":
-			v = VideoNTSC
-		case "pal":
-			v = VideoPAL
-		default:
+		v, err := ParseVideoStandard(value)
+		if err != nil {
 			v, _ = DetectVideoStandardFromROM(e.mem.rom)
 		}
 		if v != e.videoStd {
@@ -307,39 +451,84 @@ func (e *Emulator) RunFrame() {
 	// Reset audio buffer for this frame
 	e.audioBuffer = e.audioBuffer[:0]
 
+	// A skipped frame leaves the previous frame, and its filtered copy, in
+	// place
+	e.skipRender = e.skipCounter > 0
+	if e.skipCounter < e.frameskip {
+		e.skipCounter++
+	} else {
+		e.skipCounter = 0
+	}
+	if !e.skipRender {
+		e.filterValid = false
+	}
+
 	// Run the core emulation loop (populates e.frameSamples)
+	e.beginFrameEvents()
 	e.runScanlines()
+	e.endFrameEvents()
+
+	// Filter every rendered frame, fetched or not, so frame blending
+	// mixes consecutive emulated frames
+	if !e.skipRender && len(e.videoFilters) > 0 && e.vdp.rgbaOutput() {
+		e.applyVideoFilters(e.frame(), e.GetFramebufferStride(), e.GetActiveHeight())
+	}
 
 	// Convert float32 mono samples to int16 stereo in-place
 	// Attenuate by 0.5 to compensate for acoustic summing when both speakers
 	// play the same signal (mono duplicated to L+R doubles perceived loudness)
 	for _, sample := range e.frameSamples {
+		if e.lowPass.enabled {
+			sample = e.lowPass.process(sample)
+		}
 		intSample := int16(sample * 32767 * 0.5)
 		e.audioBuffer = append(e.audioBuffer, intSample, intSample)
 	}
 }
 
+// SetFrameskip sets how many frames are skipped after each rendered frame.
+// Skipped frames run the CPU and sound as normal but do not draw, so
+// GetFramebuffer keeps returning the last rendered frame. 0 renders every
+// frame.
+func (e *Emulator) SetFrameskip(n int) {
+	if n < 0 {
+		n = 0
+	}
+	e.frameskip = n
+	e.skipCounter = 0
+}
+
+// SetDebugConsole sends text written by the ROM to the SDSC debug console
+// (port $FD) to w. Test ROMs such as ZEXALL report results this way.
+// nil disables the console.
+func (e *Emulator) SetDebugConsole(w io.Writer) {
+	e.io.debugConsole = w
+}
+
 // GetAudioSamples returns accumulated audio samples as 16-bit stereo PCM.
 func (e *Emulator) GetAudioSamples() []int16 {
 	return e.audioBuffer
 }
 
 // HasSRAM reports whether the loaded ROM uses battery-backed save.
-// SMS cartridges always have 32KB cart RAM available.
+// SMS cartridges always have cart RAM available.
 func (e *Emulator) HasSRAM() bool {
 	return true
 }
 
-// GetSRAM returns a copy of the current SRAM contents.
+// GetSRAM returns a copy of the current SRAM contents, sized to the
+// cartridge's battery RAM (see Memory.SRAMSize).
 func (e *Emulator) GetSRAM() []byte {
-	sram := make([]byte, len(e.mem.cartRAM))
+	sram := make([]byte, e.mem.SRAMSize())
 	copy(sram, e.mem.cartRAM[:])
 	return sram
 }
 
-// SetSRAM loads SRAM contents into the emulator.
+// SetSRAM loads SRAM contents into the emulator. Saves of any size up to
+// 32KB are accepted. The used RAM mark restarts from the loaded contents.
 func (e *Emulator) SetSRAM(data []byte) {
 	copy(e.mem.cartRAM[:], data)
+	e.mem.cartRAMUsed = e.mem.contentSRAMUsed()
 }
 
 // =============================================================================
@@ -348,40 +537,31 @@ func (e *Emulator) SetSRAM(data []byte) {
 
 // SerializeSize returns the total size in bytes needed for a save state.
 func SerializeSize() int {
-	// Header: 22 bytes
-	// CPU: 47 bytes (library SerializeSize)
-	// Memory: 8KB RAM + 32KB cartRAM + 3 bankSlot + 1 ramControl = 40964 bytes
-	// VDP: 16KB VRAM + 32 CRAM + 16 regs + misc = ~16571 bytes
-	// PSG: 40 bytes (library SerializeSize)
-	// Input: 3 bytes (Port1, Port2, ioControl)
-
-	return stateHeaderSize + // 22
-		z80.SerializeSize + // CPU state
-		0x2000 + // RAM (8KB)
-		0x8000 + // Cart RAM (32KB)
-		3 + // bankSlot
-		1 + // ramControl
-		0x4000 + // VRAM (16KB)
-		0x20 + // CRAM (32 bytes)
-		0x20 + // CRAM latch (32 bytes)
-		16 + // VDP registers
-		2 + // addr
-		4 + // addrLatch, writeLatch, codeReg, readBuffer
-		1 + // status
-		2 + // vCounter
-		1 + // hCounter
-		2 + // lineCounter
-		1 + // lineIntPending
-		4 + // hScrollLatch, reg2Latch, reg7Latch, vScrollLatch
-		1 + // interruptCheckRequired
-		sn76489.SerializeSize + // PSG state
-		3 // Input ports (2) + ioControl (1)
+	size := stateHeaderSize
+	for _, sec := range stateSections {
+		size += stateChunkHeaderSize + sec.size
+	}
+	return size
 }
 
 // Serialize creates a save state and returns it as a byte slice.
 func (e *Emulator) Serialize() ([]byte, error) {
+	data := make([]byte, SerializeSize())
+	if err := e.SerializeInto(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// SerializeInto writes a save state into data, which must be at least
+// SerializeSize bytes. Frontends that save every frame, such as rewind
+// and run-ahead, can reuse one buffer instead of allocating a new state.
+func (e *Emulator) SerializeInto(data []byte) error {
 	size := SerializeSize()
-	data := make([]byte, size)
+	if len(data) < size {
+		return errors.New("save state buffer too small")
+	}
+	data = data[:size]
 
 	// Write header
 	copy(data[0:12], stateMagic)
@@ -391,60 +571,49 @@ func (e *Emulator) Serialize() ([]byte, error) {
 
 	offset := stateHeaderSize
 
-	// Serialize CPU state
-	offset = e.serializeCPU(data, offset)
-
-	// Serialize Memory state
-	offset = e.serializeMemory(data, offset)
-
-	// Serialize VDP state
-	offset = e.serializeVDP(data, offset)
-
-	// Serialize PSG state
-	offset = e.serializePSG(data, offset)
-
-	// Serialize Input state
-	offset = e.serializeInput(data, offset)
+	// Write each section as a tagged chunk
+	for _, sec := range stateSections {
+		copy(data[offset:], sec.tag)
+		binary.LittleEndian.PutUint32(data[offset+4:], uint32(sec.size))
+		offset += stateChunkHeaderSize
+		offset = sec.save(e, data, offset)
+	}
 
 	// Calculate and write data CRC32 (over everything after header)
 	dataCRC := crc32.ChecksumIEEE(data[stateHeaderSize:])
 	binary.LittleEndian.PutUint32(data[18:22], dataCRC)
 
-	return data, nil
+	return nil
 }
 
 // Deserialize restores emulator state from a save state byte slice.
+// Both the chunked v2 format and flat v1 states are accepted.
 // Note: Video standard is NOT restored - the current setting is preserved.
 func (e *Emulator) Deserialize(data []byte) error {
 	if err := e.VerifyState(data); err != nil {
 		return err
 	}
 
-	offset := stateHeaderSize
-
-	// Deserialize CPU state
-	offset = e.deserializeCPU(data, offset)
-
-	// Deserialize Memory state
-	offset = e.deserializeMemory(data, offset)
-
-	// Deserialize VDP state
-	offset = e.deserializeVDP(data, offset)
-
-	// Deserialize PSG state
-	offset = e.deserializePSG(data, offset)
+	if binary.LittleEndian.Uint16(data[12:14]) == 1 {
+		e.deserializeV1(data)
+		return nil
+	}
 
-	// Deserialize Input state
-	e.deserializeInput(data, offset)
+	var chunks stateChunks
+	if err := readStateChunks(data, &chunks); err != nil {
+		return err
+	}
+	// Sections load in a fixed order regardless of their order in the file
+	for i, sec := range stateSections {
+		sec.load(e, chunks[i], 0)
+	}
 
 	return nil
 }
 
 // VerifyState checks if a save state is valid without loading it.
 func (e *Emulator) VerifyState(data []byte) error {
-	// Check minimum length (must be at least header + expected state data)
-	expectedSize := SerializeSize()
-	if len(data) < expectedSize {
+	if len(data) < stateHeaderSize {
 		return errors.New("save state too short")
 	}
 
@@ -458,6 +627,9 @@ func (e *Emulator) VerifyState(data []byte) error {
 	if version > stateVersion {
 		return errors.New("unsupported save state version")
 	}
+	if version == 1 && len(data) < stateV1Size() {
+		return errors.New("save state too short")
+	}
 
 	// Check ROM CRC32
 	romCRC := binary.LittleEndian.Uint32(data[14:18])
@@ -472,6 +644,13 @@ func (e *Emulator) VerifyState(data []byte) error {
 		return errors.New("save state data is corrupted")
 	}
 
+	if version >= 2 {
+		var chunks stateChunks
+		if err := readStateChunks(data, &chunks); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -505,6 +684,10 @@ func (e *Emulator) serializeMemory(data []byte, offset int) int {
 	data[offset] = e.mem.ramControl
 	offset++
 
+	// Used cart RAM mark (2 bytes, appended after the v1 fields)
+	binary.LittleEndian.PutUint16(data[offset:], uint16(e.mem.cartRAMUsed))
+	offset += 2
+
 	return offset
 }
 
@@ -526,6 +709,15 @@ func (e *Emulator) deserializeMemory(data []byte, offset int) int {
 	e.mem.ramControl = data[offset]
 	offset++
 
+	// Used cart RAM mark (2 bytes). Older states did not store it, so it
+	// is derived from the cart RAM they hold.
+	if len(data)-offset >= 2 {
+		e.mem.cartRAMUsed = int(binary.LittleEndian.Uint16(data[offset:]))
+		offset += 2
+	} else {
+		e.mem.cartRAMUsed = e.mem.contentSRAMUsed()
+	}
+
 	return offset
 }
 
@@ -607,6 +799,16 @@ func (e *Emulator) serializeVDP(data []byte, offset int) int {
 	}
 	offset++
 
+	// H counter latch (2 bytes, appended after the v1 fields)
+	data[offset] = e.vdp.hLatch
+	offset++
+	if e.vdp.hLatched {
+		data[offset] = 1
+	} else {
+		data[offset] = 0
+	}
+	offset++
+
 	return offset
 }
 
@@ -615,6 +817,7 @@ func (e *Emulator) deserializeVDP(data []byte, offset int) int {
 	// VRAM (16KB)
 	copy(e.vdp.vram[:], data[offset:offset+len(e.vdp.vram)])
 	offset += len(e.vdp.vram)
+	e.vdp.tiles.invalidateAll()
 
 	// CRAM (32 bytes)
 	copy(e.vdp.cram[:], data[offset:offset+len(e.vdp.cram)])
@@ -676,6 +879,14 @@ func (e *Emulator) deserializeVDP(data []byte, offset int) int {
 	e.vdp.interruptCheckRequired = data[offset] != 0
 	offset++
 
+	// H counter latch (2 bytes), absent from older states
+	e.vdp.hLatch, e.vdp.hLatched = 0, false
+	if len(data)-offset >= 2 {
+		e.vdp.hLatch = data[offset]
+		e.vdp.hLatched = data[offset+1] != 0
+		offset += 2
+	}
+
 	return offset
 }
 
@@ -688,6 +899,10 @@ func (e *Emulator) serializePSG(data []byte, offset int) int {
 // deserializePSG reads PSG state from the data buffer
 func (e *Emulator) deserializePSG(data []byte, offset int) int {
 	e.psg.Deserialize(data[offset:])
+	e.io.mixer.resync()
+	// The output filter is not part of the state; clearing it makes a
+	// loaded state produce the same audio in every instance
+	e.lowPass.state = 0
 	return offset + sn76489.SerializeSize
 }
 
@@ -699,6 +914,13 @@ func (e *Emulator) serializeInput(data []byte, offset int) int {
 	offset++
 	data[offset] = e.io.ioControl
 	offset++
+	// Pause button level (1 byte, appended after the v1 fields)
+	if e.pauseHeld {
+		data[offset] = 1
+	} else {
+		data[offset] = 0
+	}
+	offset++
 	return offset
 }
 
@@ -710,6 +932,12 @@ func (e *Emulator) deserializeInput(data []byte, offset int) int {
 	offset++
 	e.io.ioControl = data[offset]
 	offset++
+	// Pause button level (1 byte), absent from older states
+	e.pauseHeld = false
+	if len(data)-offset >= 1 {
+		e.pauseHeld = data[offset] != 0
+		offset++
+	}
 	return offset
 }
 
@@ -748,19 +976,22 @@ func (e *Emulator) ReadMemory(addr uint32, buf []byte) uint32 {
 func (e *Emulator) MemoryMap() []coreif.MemoryRegion {
 	return []coreif.MemoryRegion{
 		{Type: coreif.MemorySystemRAM, Size: 0x2000},
-		{Type: coreif.MemorySaveRAM, Size: 0x8000},
+		{Type: coreif.MemorySaveRAM, Size: e.mem.mappedSRAMSize()},
 	}
 }
 
-// ReadRegion returns a copy of the specified memory region.
+// ReadRegion returns a copy of the specified memory region. Frontends
+// call this every frame, so the copy is made into a buffer owned by the
+// emulator and is only valid until the next ReadRegion for that region.
 func (e *Emulator) ReadRegion(regionType int) []byte {
 	switch regionType {
 	case coreif.MemorySystemRAM:
-		out := make([]byte, len(e.mem.ram))
-		copy(out, e.mem.ram[:])
-		return out
+		copy(e.regionBuffers.ram[:], e.mem.ram[:])
+		return e.regionBuffers.ram[:]
 	case coreif.MemorySaveRAM:
-		return e.GetSRAM()
+		size := e.mem.mappedSRAMSize()
+		copy(e.regionBuffers.cartRAM[:size], e.mem.cartRAM[:])
+		return e.regionBuffers.cartRAM[:size]
 	default:
 		return nil
 	}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"bytes"
 	"encoding/binary"
 	"hash/crc32"
 	"testing"
@@ -139,6 +140,109 @@ func TestEmulator_FixedPointTiming(t *testing.T) {
 	}
 }
 
+// TestEmulator_FrameCycles tests that a frame runs CPUClockHz/FPS cycles so
+// audio and video match the FPS reported by GetTiming
+func TestEmulator_FrameCycles(t *testing.T) {
+	for name, std := range map[string]VideoStandard{"NTSC": VideoNTSC, "PAL": VideoPAL} {
+		e := createTestEmulator()
+		e.setVideoStandard(std)
+		e.RunFrame()
+
+		before := e.cpu.Cycles()
+		e.RunFrame()
+		got := int(e.cpu.Cycles() - before)
+		want := e.timing.CPUClockHz / e.timing.FPS
+		// A final instruction may overrun into the next frame
+		if got < want-23 || got > want+23 {
+			t.Errorf("%s: expected ~%d cycles per frame, got %d", name, want, got)
+		}
+	}
+}
+
+// TestEmulator_VBlankInterruptTiming tests that the frame interrupt is
+// asserted at VBlankInterruptCycle of the line after the active display.
+// The CPU waits in HALT (4-cycle steps) and the IM 1 acknowledge takes 13
+// cycles, so the handler's first instruction starts 17-20 cycles into the
+// line.
+func TestEmulator_VBlankInterruptTiming(t *testing.T) {
+	rom := make([]byte, 0x8000)
+	copy(rom, []byte{
+		0xF3,       // DI
+		0xED, 0x56, // IM 1
+		0x31, 0xF0, 0xDF, // LD SP,$DFF0
+		0x21, 0x00, 0xC0, // LD HL,$C000
+		0x3E, 0x60, 0xD3, 0xBF, 0x3E, 0x81, 0xD3, 0xBF, // Reg 1: display on, IE0
+		0xFB,       // EI
+		0x76,       // HALT
+		0x18, 0xFD, // JR to HALT
+	})
+	copy(rom[0x38:], []byte{
+		0xDB, 0x7F, // IN A,($7F) ; H counter
+		0x77,       // LD (HL),A
+		0x2C,       // INC L
+		0xDB, 0x7E, // IN A,($7E) ; V counter
+		0x77,       // LD (HL),A
+		0x2C,       // INC L
+		0xDB, 0xBF, // IN A,($BF) ; acknowledge
+		0xFB,       // EI
+		0xED, 0x4D, // RETI
+	})
+	e, _ := NewEmulator(rom)
+	e.setVideoStandard(VideoNTSC)
+	e.RunFrame()
+	e.RunFrame()
+
+	if e.mem.ram[3] == 0 {
+		t.Fatal("Frame interrupt was not taken twice")
+	}
+	lo := GetHCounterForCycle(VBlankInterruptCycle + 13)
+	hi := GetHCounterForCycle(VBlankInterruptCycle + 3 + 13)
+	for i := 0; i < 4; i += 2 {
+		h, v := e.mem.ram[i], e.mem.ram[i+1]
+		if v != 0xC1 {
+			t.Errorf("Interrupt %d: expected V counter 0xC1, got 0x%02X", i/2, v)
+		}
+		if h < lo || h > hi {
+			t.Errorf("Interrupt %d: expected H counter 0x%02X-0x%02X, got 0x%02X", i/2, lo, hi, h)
+		}
+	}
+}
+
+// TestEmulator_EIDelay tests that an interrupt already pending when EI
+// runs is accepted only after the following instruction
+func TestEmulator_EIDelay(t *testing.T) {
+	rom := make([]byte, 0x8000)
+	copy(rom, []byte{
+		0xF3,       // DI
+		0xED, 0x56, // IM 1
+		0x31, 0xF0, 0xDF, // LD SP,$DFF0
+		0x3E, 0x00, 0xD3, 0xBF, 0x3E, 0x8A, 0xD3, 0xBF, // Reg 10 = 0: interrupt every line
+		0x3E, 0x10, 0xD3, 0xBF, 0x3E, 0x80, 0xD3, 0xBF, // Reg 0: IE1
+		0x01, 0x00, 0x10, // LD BC,$1000
+		0x0B,       // DEC BC ; wait until a line interrupt is pending
+		0x78,       // LD A,B
+		0xB1,       // OR C
+		0x20, 0xFB, // JR NZ,DEC BC
+		0xAF,       // XOR A
+		0xFB,       // EI
+		0x3C,       // INC A
+		0x3C,       // INC A
+		0x18, 0xFE, // JR $
+	})
+	copy(rom[0x38:], []byte{
+		0x32, 0x00, 0xC0, // LD ($C000),A
+		0x18, 0xFE, // JR $
+	})
+	e, _ := NewEmulator(rom)
+	for i := 0; i < 3; i++ {
+		e.RunFrame()
+	}
+
+	if got := e.mem.ram[0]; got != 1 {
+		t.Errorf("Expected one instruction after EI before the interrupt (A=1), got A=%d", got)
+	}
+}
+
 // TestEmulator_ScanlineExecution tests one scanline of execution
 func TestEmulator_ScanlineExecution(t *testing.T) {
 	rom := createTestROM(4)
@@ -337,8 +441,8 @@ func TestEmulator_Constants(t *testing.T) {
 	if ScreenWidth != 256 {
 		t.Errorf("ScreenWidth: expected 256, got %d", ScreenWidth)
 	}
-	if MaxScreenHeight != 224 {
-		t.Errorf("MaxScreenHeight: expected 224, got %d", MaxScreenHeight)
+	if MaxScreenHeight != 240 {
+		t.Errorf("MaxScreenHeight: expected 240, got %d", MaxScreenHeight)
 	}
 }
 
@@ -533,6 +637,194 @@ func TestSerializeDeserializeRoundTrip(t *testing.T) {
 	}
 }
 
+// TestSerialize_SizeStable tests that every state has the advertised size
+// regardless of emulated state, which frontend rewind and netplay rely on
+func TestSerialize_SizeStable(t *testing.T) {
+	e := createTestEmulator()
+
+	check := func(when string) {
+		state, err := e.Serialize()
+		if err != nil {
+			t.Fatalf("Serialize %s failed: %v", when, err)
+		}
+		if len(state) != SerializeSize() {
+			t.Errorf("State size %s: expected %d, got %d", when, SerializeSize(), len(state))
+		}
+	}
+
+	check("at power-on")
+	for i := 0; i < 3; i++ {
+		e.RunFrame()
+	}
+	check("after frames")
+
+	e.mem.Set(0xFFFC, 0x08) // Map cart RAM
+	e.mem.Set(0x8000, 0x42)
+	check("with cart RAM")
+
+	e.SetOption("video_standard", "pal")
+	e.RunFrame()
+	check("after switching to PAL")
+}
+
+// TestSerialize_RewindDeterminism tests that loading a state and running
+// reproduces the same frames and state as the original run
+func TestSerialize_RewindDeterminism(t *testing.T) {
+	e := createTestEmulator()
+	e.vdp.register[1] = 0x40 // Display on
+	for i := 0; i < 10; i++ {
+		e.RunFrame()
+	}
+
+	start, err := e.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	run := func() ([]byte, []byte) {
+		for i := 0; i < 5; i++ {
+			e.RunFrame()
+		}
+		state, err := e.Serialize()
+		if err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+		return state, append([]byte(nil), e.GetFramebuffer()...)
+	}
+
+	state1, frame1 := run()
+	if err := e.Deserialize(start); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	state2, frame2 := run()
+
+	if !bytes.Equal(state1, state2) {
+		t.Errorf("State after replay differs from original run")
+	}
+	if !bytes.Equal(frame1, frame2) {
+		t.Errorf("Frame after replay differs from original run")
+	}
+}
+
+// TestSerialize_SecondInstance tests that a state loaded into a separate
+// emulator, as run-ahead's second instance does, reproduces the original
+// run's frames and audio
+func TestSerialize_SecondInstance(t *testing.T) {
+	e := createTestEmulator()
+	e.SetOption("audio_lowpass", "true")
+	e.vdp.register[1] = 0x40 // Display on
+	for _, b := range []uint8{0x8E, 0x0F, 0x90} {
+		e.io.mixer.write(b) // Tone 0 audible
+	}
+	for i := 0; i < 3; i++ {
+		e.RunFrame()
+	}
+
+	buf := make([]byte, SerializeSize())
+	if err := e.SerializeInto(buf); err != nil {
+		t.Fatalf("SerializeInto failed: %v", err)
+	}
+	second := createTestEmulator()
+	second.SetOption("audio_lowpass", "true")
+	if err := second.Deserialize(buf); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if err := e.Deserialize(buf); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		e.RunFrame()
+		second.RunFrame()
+		if !bytes.Equal(e.GetFramebuffer(), second.GetFramebuffer()) {
+			t.Fatalf("Frame %d differs between instances", i)
+		}
+		if len(e.GetAudioSamples()) == 0 {
+			t.Fatalf("Frame %d produced no audio", i)
+		}
+		for j, s := range e.GetAudioSamples() {
+			if second.GetAudioSamples()[j] != s {
+				t.Fatalf("Frame %d audio differs between instances at sample %d", i, j)
+			}
+		}
+	}
+}
+
+// TestSerialize_PauseHeldDeterminism tests that a state saved while pause
+// is held replays the same inputs without raising a second NMI, in the
+// same instance and in a fresh one
+func TestSerialize_PauseHeldDeterminism(t *testing.T) {
+	e := createTestEmulator()
+	e.vdp.register[1] = 0x40 // Display on
+	e.RunFrame()
+	e.SetPauseButton(true)
+	e.RunFrame()
+
+	start, err := e.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	run := func(emu *Emulator) ([]byte, FrameEvent) {
+		var events FrameEvent
+		for i := 0; i < 3; i++ {
+			emu.SetPauseButton(true) // Still held
+			emu.RunFrame()
+			events |= emu.FrameEvents()
+		}
+		state, err := emu.Serialize()
+		if err != nil {
+			t.Fatalf("Serialize failed: %v", err)
+		}
+		return state, events
+	}
+
+	state1, events1 := run(e)
+	if events1&EventPause != 0 {
+		t.Fatal("Held pause raised an NMI in the original run")
+	}
+
+	if err := e.Deserialize(start); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	state2, events2 := run(e)
+	if events2 != events1 || !bytes.Equal(state1, state2) {
+		t.Errorf("Replay after a state load differs from the original run")
+	}
+
+	second := createTestEmulator()
+	if err := second.Deserialize(start); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	state3, events3 := run(second)
+	if events3 != events1 || !bytes.Equal(state1, state3) {
+		t.Errorf("Replay in a fresh instance differs from the original run")
+	}
+}
+
+// TestSerialize_NoAlloc tests that saving into a reused buffer and
+// loading do not allocate
+func TestSerialize_NoAlloc(t *testing.T) {
+	e := createTestEmulator()
+	buf := make([]byte, SerializeSize())
+
+	allocs := testing.AllocsPerRun(10, func() {
+		if err := e.SerializeInto(buf); err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Deserialize(buf); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Save and load allocated %.0f times per run", allocs)
+	}
+
+	if err := e.SerializeInto(buf[:len(buf)-1]); err == nil {
+		t.Error("SerializeInto a short buffer should fail")
+	}
+}
+
 // TestVerifyState_ValidState tests that a valid state passes verification
 func TestVerifyState_ValidState(t *testing.T) {
 	base := createTestEmulator()
@@ -801,6 +1093,27 @@ func TestEmulator_SetInput_Player2(t *testing.T) {
 	}
 }
 
+// TestEmulator_SetInput_SwapButtons tests the swap_buttons option
+func TestEmulator_SetInput_SwapButtons(t *testing.T) {
+	e := createTestEmulator()
+	e.SetOption("swap_buttons", "true")
+
+	// Button 1 pressed should reach the console as button 2
+	e.SetInput(0, 1<<4)
+	if e.io.Input.Port1&0x10 == 0 {
+		t.Error("Button 1 should be released when swapped")
+	}
+	if e.io.Input.Port1&0x20 != 0 {
+		t.Error("Button 2 should be pressed when swapped")
+	}
+
+	e.SetOption("swap_buttons", "false")
+	e.SetInput(0, 1<<4)
+	if e.io.Input.Port1&0x10 != 0 {
+		t.Error("Button 1 should be pressed when not swapped")
+	}
+}
+
 // TestEmulator_SetInput_PauseEdge tests pause NMI edge detection
 func TestEmulator_SetInput_PauseEdge(t *testing.T) {
 	e := createTestEmulator()
@@ -819,6 +1132,36 @@ func TestEmulator_SetInput_PauseEdge(t *testing.T) {
 	e.SetInput(0, 1<<7)
 }
 
+// TestEmulator_SetPauseButton tests that only a press raises an NMI
+func TestEmulator_SetPauseButton(t *testing.T) {
+	e := createTestEmulator()
+
+	steps := []struct {
+		pressed bool
+		nmi     bool
+	}{
+		{false, false},
+		{true, true},
+		{true, false}, // Held
+		{false, false},
+		{true, true},
+	}
+	for i, step := range steps {
+		e.inputEvents = 0
+		e.SetPauseButton(step.pressed)
+		if got := e.inputEvents&EventPause != 0; got != step.nmi {
+			t.Errorf("Step %d: expected NMI %v, got %v", i, step.nmi, got)
+		}
+	}
+
+	// SetInput shares the same button state
+	e.inputEvents = 0
+	e.SetInput(0, 1<<7)
+	if e.inputEvents&EventPause != 0 {
+		t.Error("SetInput re-triggered a pause already held by SetPauseButton")
+	}
+}
+
 // =============================================================================
 // Crop Border Tests
 // =============================================================================
@@ -856,6 +1199,118 @@ func TestEmulator_CropBorder(t *testing.T) {
 	}
 }
 
+// TestEmulator_Frameskip tests that skipped frames keep the last drawn
+// frame and still update sprite status flags
+func TestEmulator_Frameskip(t *testing.T) {
+	e := createTestEmulator()
+	e.SetOption("frameskip", "1")
+
+	setBackdrop := func(c uint8) {
+		e.vdp.WriteControl(16)
+		e.vdp.WriteControl(0xC0)
+		e.vdp.WriteData(c)
+	}
+	backdropRed := func() uint8 {
+		return e.GetFramebuffer()[0]
+	}
+
+	setBackdrop(0x03) // Red
+	e.RunFrame()
+	if got := backdropRed(); got != 255 {
+		t.Fatalf("Rendered frame: expected red 255, got %d", got)
+	}
+
+	setBackdrop(0x00) // Black
+	e.RunFrame()
+	if got := backdropRed(); got != 255 {
+		t.Errorf("Skipped frame: expected previous frame (red 255), got %d", got)
+	}
+
+	e.RunFrame()
+	if got := backdropRed(); got != 0 {
+		t.Errorf("Next rendered frame: expected black, got %d", got)
+	}
+
+	// Nine sprites on one line overflow even when the frame is skipped
+	e.RunFrame() // Rendered; the next frame is skipped
+	for i := uint16(0); i < 9; i++ {
+		e.vdp.vram[0x3F00+i] = 0x00 // Shown on lines 1-8
+	}
+	e.vdp.vram[0x3F00+9] = 0xD0
+	e.vdp.WriteControl(0xFF)
+	e.vdp.WriteControl(0x85) // SAT at 0x3F00
+	e.vdp.WriteControl(0x40)
+	e.vdp.WriteControl(0x81) // Display enabled
+	e.vdp.ReadControl()
+	e.RunFrame()
+	if e.vdp.ReadControl()&0x40 == 0 {
+		t.Error("Sprite overflow should be set on a skipped frame")
+	}
+}
+
+// TestEmulator_Overscan tests bordered frame geometry and border fill
+func TestEmulator_Overscan(t *testing.T) {
+	e := createTestEmulator()
+	e.SetOption("overscan", "true")
+
+	if got := e.GetFramebufferStride(); got != OverscanWidth*4 {
+		t.Errorf("Overscan stride: expected %d, got %d", OverscanWidth*4, got)
+	}
+	if got := e.GetActiveHeight(); got != OverscanNTSCLines {
+		t.Errorf("Overscan NTSC height: expected %d, got %d", OverscanNTSCLines, got)
+	}
+
+	// Backdrop = sprite palette entry 0 = red
+	e.vdp.WriteControl(16)
+	e.vdp.WriteControl(0xC0)
+	e.vdp.WriteData(0x03)
+
+	e.RunFrame()
+
+	fb := e.GetFramebuffer()
+	if len(fb) != OverscanWidth*4*OverscanNTSCLines {
+		t.Fatalf("Overscan framebuffer length: expected %d, got %d", OverscanWidth*4*OverscanNTSCLines, len(fb))
+	}
+
+	// Top border row, left border of first active row, bottom border row
+	for _, pos := range [][2]int{{100, 0}, {0, 27}, {OverscanWidth - 1, 27 + 100}, {100, 27 + 192}} {
+		p := pos[1]*OverscanWidth*4 + pos[0]*4
+		if fb[p] != 255 || fb[p+1] != 0 || fb[p+2] != 0 {
+			t.Errorf("Border pixel (%d, %d): expected red, got %v", pos[0], pos[1], fb[p:p+4])
+		}
+	}
+
+	e.SetOption("video_standard", "pal")
+	if got := e.GetActiveHeight(); got != OverscanPALLines {
+		t.Errorf("Overscan PAL height: expected %d, got %d", OverscanPALLines, got)
+	}
+
+	e.SetOption("overscan", "false")
+	if got := e.GetFramebufferStride(); got != ScreenWidth*4 {
+		t.Errorf("Stride after disabling overscan: expected %d, got %d", ScreenWidth*4, got)
+	}
+}
+
+// TestOverscanBorders tests that each mode fills the constant frame height
+func TestOverscanBorders(t *testing.T) {
+	testCases := []struct {
+		std    VideoStandard
+		active int
+	}{
+		{VideoNTSC, 192},
+		{VideoNTSC, 224},
+		{VideoPAL, 192},
+		{VideoPAL, 224},
+		{VideoPAL, 240},
+	}
+	for _, tc := range testCases {
+		top, bottom := overscanBorders(tc.std, tc.active)
+		if got := top + tc.active + bottom; got != overscanHeight(tc.std) {
+			t.Errorf("std=%d active=%d: frame height %d, expected %d", tc.std, tc.active, got, overscanHeight(tc.std))
+		}
+	}
+}
+
 // =============================================================================
 // BatterySaver Tests
 // =============================================================================
@@ -965,3 +1420,46 @@ func TestEmulator_ReadWriteRegion(t *testing.T) {
 			result[0], result[1])
 	}
 }
+
+// TestEmulator_ReadRegion_NoAlloc tests that per-frame region reads reuse
+// their buffers and still reflect the latest memory contents
+func TestEmulator_ReadRegion_NoAlloc(t *testing.T) {
+	e := createTestEmulator()
+
+	allocs := testing.AllocsPerRun(10, func() {
+		e.ReadRegion(1) // MemorySystemRAM = 1
+		e.ReadRegion(0) // MemorySaveRAM = 0
+	})
+	if allocs != 0 {
+		t.Errorf("ReadRegion allocated %.0f times per run", allocs)
+	}
+
+	e.mem.cartRAM[5] = 0x42
+	if got := e.ReadRegion(0)[5]; got != 0x42 {
+		t.Errorf("ReadRegion save RAM: expected 0x42, got 0x%02X", got)
+	}
+}
+
+// TestEmulator_Unsupported240Line tests that 240-line mode is reported
+// only where the video standard cannot display it
+func TestEmulator_Unsupported240Line(t *testing.T) {
+	e := createTestEmulator()
+	e.setVideoStandard(VideoNTSC)
+	if e.Unsupported240Line() {
+		t.Error("192-line mode reported as unsupported 240-line mode")
+	}
+
+	// M2 and M3 select 240-line mode
+	e.vdp.WriteControl(0x06)
+	e.vdp.WriteControl(0x80)
+	e.vdp.WriteControl(0x08)
+	e.vdp.WriteControl(0x81)
+	if !e.Unsupported240Line() {
+		t.Error("240-line mode on NTSC should be reported")
+	}
+
+	e.setVideoStandard(VideoPAL)
+	if e.Unsupported240Line() {
+		t.Error("240-line mode on PAL should not be reported")
+	}
+}
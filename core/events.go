@@ -0,0 +1,36 @@
+package core
+
+// FrameEvent flags report things that happened during the last RunFrame
+// that a frontend may want to signal to the user, such as blinking an LED
+// on battery saves or a short rumble on pause.
+type FrameEvent uint32
+
+const (
+	// EventSRAMWrite is set when the game wrote cartridge RAM
+	EventSRAMWrite FrameEvent = 1 << iota
+	// EventPause is set when the pause button raised an NMI. Pause input
+	// is taken by SetInput before the frame, so the event is reported by
+	// the RunFrame that follows.
+	EventPause
+)
+
+// FrameEvents returns the events raised by the last RunFrame.
+func (e *Emulator) FrameEvents() FrameEvent {
+	return e.events
+}
+
+// beginFrameEvents starts event collection for a frame, carrying over
+// events raised by input since the previous frame
+func (e *Emulator) beginFrameEvents() {
+	e.events = e.inputEvents
+	e.inputEvents = 0
+	e.mem.cartRAMWritten = false
+}
+
+// endFrameEvents records events raised by the hardware during the frame
+func (e *Emulator) endFrameEvents() {
+	if e.mem.cartRAMWritten {
+		e.events |= EventSRAMWrite
+	}
+	e.updateSRAMDirty()
+}
@@ -0,0 +1,53 @@
+package core
+
+import "testing"
+
+// TestFrameEvents_SRAMWrite tests that a cartridge RAM write is reported
+// for the frame it happened in only
+func TestFrameEvents_SRAMWrite(t *testing.T) {
+	rom := createTestROM(4)
+	copy(rom, []byte{
+		0xF3,       // DI
+		0x3E, 0x08, // LD A,$08
+		0x32, 0xFC, 0xFF, // LD ($FFFC),A ; map cart RAM at $8000
+		0x32, 0x00, 0x80, // LD ($8000),A
+		0x18, 0xFE, // JR $
+	})
+	e, err := NewEmulator(rom)
+	if err != nil {
+		t.Fatalf("NewEmulator failed: %v", err)
+	}
+
+	e.RunFrame()
+	if e.FrameEvents()&EventSRAMWrite == 0 {
+		t.Error("First frame should report an SRAM write")
+	}
+	e.RunFrame()
+	if e.FrameEvents()&EventSRAMWrite != 0 {
+		t.Error("Second frame should not report an SRAM write")
+	}
+}
+
+// TestFrameEvents_Pause tests that a pause press is reported by the
+// following frame
+func TestFrameEvents_Pause(t *testing.T) {
+	e := createTestEmulator()
+
+	e.RunFrame()
+	if e.FrameEvents() != 0 {
+		t.Errorf("Idle frame: expected no events, got %#x", e.FrameEvents())
+	}
+
+	e.SetInput(0, 1<<7)
+	e.RunFrame()
+	if e.FrameEvents()&EventPause == 0 {
+		t.Error("Frame after pause press should report EventPause")
+	}
+
+	// Holding pause does not raise another NMI
+	e.SetInput(0, 1<<7)
+	e.RunFrame()
+	if e.FrameEvents()&EventPause != 0 {
+		t.Error("Held pause should not report EventPause again")
+	}
+}
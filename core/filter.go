@@ -0,0 +1,247 @@
+package core
+
+// VideoFilter post-processes a finished RGBA frame before it is handed to
+// a frontend. src and dst are distinct buffers with the same geometry;
+// width and height are in pixels and stride is in bytes.
+type VideoFilter interface {
+	Apply(dst, src []byte, width, height, stride int)
+}
+
+// Built-in filter names accepted by the video_filter option
+const (
+	FilterNone      = "none"
+	FilterScanlines = "scanlines"
+	FilterPhosphor  = "phosphor"
+	FilterNTSC      = "ntsc"
+)
+
+// NewVideoFilter returns the built-in filter for name, or nil for "none"
+// and unknown names.
+func NewVideoFilter(name string) VideoFilter {
+	switch name {
+	case FilterScanlines:
+		return &ScanlineFilter{Intensity: 0.5}
+	case FilterPhosphor:
+		return &PhosphorFilter{}
+	case FilterNTSC:
+		return &NTSCFilter{}
+	default:
+		return nil
+	}
+}
+
+// ScanlineFilter darkens every other row to mimic the gaps between
+// scanlines on a CRT. Intensity is the fraction of brightness removed
+// from the dark rows (0 = no effect, 1 = black).
+type ScanlineFilter struct {
+	Intensity float32
+}
+
+// Apply implements VideoFilter.
+func (f *ScanlineFilter) Apply(dst, src []byte, width, height, stride int) {
+	keep := uint32((1 - f.Intensity) * 256)
+	for y := 0; y < height; y++ {
+		off := y * stride
+		row := src[off : off+width*4]
+		out := dst[off : off+width*4]
+		if y&1 == 0 {
+			copy(out, row)
+			continue
+		}
+		for x := 0; x < width*4; x += 4 {
+			out[x] = uint8(uint32(row[x]) * keep >> 8)
+			out[x+1] = uint8(uint32(row[x+1]) * keep >> 8)
+			out[x+2] = uint8(uint32(row[x+2]) * keep >> 8)
+			out[x+3] = row[x+3]
+		}
+	}
+}
+
+// PhosphorFilter applies a 1-2-1 horizontal blur to mimic phosphor glow
+// bleeding into neighboring pixels.
+type PhosphorFilter struct{}
+
+// Apply implements VideoFilter.
+func (f *PhosphorFilter) Apply(dst, src []byte, width, height, stride int) {
+	for y := 0; y < height; y++ {
+		off := y * stride
+		for x := 0; x < width; x++ {
+			l := x - 1
+			if l < 0 {
+				l = 0
+			}
+			r := x + 1
+			if r >= width {
+				r = width - 1
+			}
+			p := off + x*4
+			pl := off + l*4
+			pr := off + r*4
+			for c := 0; c < 3; c++ {
+				dst[p+c] = uint8((uint32(src[pl+c]) + 2*uint32(src[p+c]) + uint32(src[pr+c])) >> 2)
+			}
+			dst[p+3] = src[p+3]
+		}
+	}
+}
+
+// NTSCFilter approximates composite video artifacts. Each row is converted
+// to YIQ, the chroma (I/Q) channels are low-passed over a wide window while
+// luma is only lightly smoothed, then the row is converted back. The result
+// is the color bleed and softening of a composite signal.
+type NTSCFilter struct {
+	yiq []float32 // Scratch row: Y, I, Q per pixel
+}
+
+// ntscChromaTaps is the chroma low-pass half-width in pixels
+const ntscChromaTaps = 2
+
+// Apply implements VideoFilter.
+func (f *NTSCFilter) Apply(dst, src []byte, width, height, stride int) {
+	if len(f.yiq) < width*3 {
+		f.yiq = make([]float32, width*3)
+	}
+	yiq := f.yiq
+
+	for y := 0; y < height; y++ {
+		off := y * stride
+
+		for x := 0; x < width; x++ {
+			p := off + x*4
+			r := float32(src[p])
+			g := float32(src[p+1])
+			b := float32(src[p+2])
+			yiq[x*3] = 0.299*r + 0.587*g + 0.114*b
+			yiq[x*3+1] = 0.596*r - 0.274*g - 0.322*b
+			yiq[x*3+2] = 0.211*r - 0.523*g + 0.312*b
+		}
+
+		for x := 0; x < width; x++ {
+			// Luma: 1-2-1 blur
+			l := x - 1
+			if l < 0 {
+				l = 0
+			}
+			rr := x + 1
+			if rr >= width {
+				rr = width - 1
+			}
+			luma := (yiq[l*3] + 2*yiq[x*3] + yiq[rr*3]) / 4
+
+			// Chroma: box filter over 2*ntscChromaTaps+1 pixels
+			var ci, cq float32
+			for k := -ntscChromaTaps; k <= ntscChromaTaps; k++ {
+				sx := x + k
+				if sx < 0 {
+					sx = 0
+				} else if sx >= width {
+					sx = width - 1
+				}
+				ci += yiq[sx*3+1]
+				cq += yiq[sx*3+2]
+			}
+			ci /= 2*ntscChromaTaps + 1
+			cq /= 2*ntscChromaTaps + 1
+
+			p := off + x*4
+			dst[p] = clampByte(luma + 0.956*ci + 0.621*cq)
+			dst[p+1] = clampByte(luma - 0.272*ci - 0.647*cq)
+			dst[p+2] = clampByte(luma - 1.106*ci + 1.703*cq)
+			dst[p+3] = src[p+3]
+		}
+	}
+}
+
+// FrameBlendFilter averages each frame with the previous one. Games that
+// flicker sprites on alternate frames for transparency then appear as a
+// steady blend, as they would through the persistence of an LCD or a
+// slow CRT phosphor. Each Apply call is taken as a new frame.
+type FrameBlendFilter struct {
+	prev []byte // Previous unfiltered frame
+}
+
+// Apply implements VideoFilter.
+func (f *FrameBlendFilter) Apply(dst, src []byte, width, height, stride int) {
+	n := stride * height
+	if len(f.prev) != n {
+		// First frame or geometry change: nothing to blend with yet
+		f.prev = append(f.prev[:0], src[:n]...)
+		copy(dst[:n], src[:n])
+		return
+	}
+	for y := 0; y < height; y++ {
+		off := y * stride
+		for x := off; x < off+width*4; x += 4 {
+			dst[x] = uint8((uint16(src[x]) + uint16(f.prev[x]) + 1) >> 1)
+			dst[x+1] = uint8((uint16(src[x+1]) + uint16(f.prev[x+1]) + 1) >> 1)
+			dst[x+2] = uint8((uint16(src[x+2]) + uint16(f.prev[x+2]) + 1) >> 1)
+			dst[x+3] = src[x+3]
+		}
+	}
+	copy(f.prev, src[:n])
+}
+
+// clampByte rounds and clamps a float to the 0-255 range
+func clampByte(v float32) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 255 {
+		return 255
+	}
+	return uint8(v + 0.5)
+}
+
+// SetVideoFilters replaces the post-processing chain. Filters are applied
+// in order to the frame returned by GetFramebuffer. Passing no filters
+// disables post-processing.
+func (e *Emulator) SetVideoFilters(filters ...VideoFilter) {
+	e.videoFilters = e.videoFilters[:0]
+	for _, f := range filters {
+		if f != nil {
+			e.videoFilters = append(e.videoFilters, f)
+		}
+	}
+	if len(e.videoFilters) > 0 && e.filterBuffers[0] == nil {
+		size := OverscanWidth * MaxOverscanHeight * 4
+		e.filterBuffers[0] = make([]byte, size)
+		e.filterBuffers[1] = make([]byte, size)
+	}
+	e.filterValid = false
+}
+
+// updateVideoFilters rebuilds the chain from the video_filter and
+// frame_blend options. Blending runs first so it mixes raw frames.
+func (e *Emulator) updateVideoFilters() {
+	var blend VideoFilter
+	if e.frameBlend {
+		blend = &FrameBlendFilter{}
+	}
+	e.SetVideoFilters(blend, NewVideoFilter(e.videoFilterName))
+}
+
+// applyVideoFilters runs the filter chain over frame and returns the
+// filtered result. RunFrame calls it once per rendered frame and the
+// result is cached until the next RunFrame, so repeated GetFramebuffer
+// calls do not re-filter. An option change that invalidates the cache
+// re-filters the same frame; FrameBlendFilter then blends the frame with
+// itself, once, and its history stays correct for the next frame.
+func (e *Emulator) applyVideoFilters(frame []byte, stride, height int) []byte {
+	n := stride * height
+	if e.filterValid {
+		return e.filterBuffers[e.filterOut][:n]
+	}
+
+	width := stride / 4
+	src := frame
+	out := 0
+	for _, f := range e.videoFilters {
+		dst := e.filterBuffers[out][:n]
+		f.Apply(dst, src, width, height, stride)
+		src = dst
+		out ^= 1
+	}
+	e.filterOut = out ^ 1
+	e.filterValid = true
+	return src
+}
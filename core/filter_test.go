@@ -0,0 +1,187 @@
+package core
+
+import "testing"
+
+// makeFrame creates an RGBA frame filled with a single color
+func makeFrame(width, height int, r, g, b uint8) []byte {
+	frame := make([]byte, width*height*4)
+	for i := 0; i < len(frame); i += 4 {
+		frame[i] = r
+		frame[i+1] = g
+		frame[i+2] = b
+		frame[i+3] = 0xFF
+	}
+	return frame
+}
+
+// TestScanlineFilter tests that odd rows are darkened and even rows kept
+func TestScanlineFilter(t *testing.T) {
+	src := makeFrame(4, 4, 200, 100, 50)
+	dst := make([]byte, len(src))
+
+	f := &ScanlineFilter{Intensity: 0.5}
+	f.Apply(dst, src, 4, 4, 16)
+
+	if dst[0] != 200 || dst[1] != 100 || dst[2] != 50 {
+		t.Errorf("Even row: expected unchanged, got %v", dst[0:4])
+	}
+	odd := 16
+	if dst[odd] != 100 || dst[odd+1] != 50 || dst[odd+2] != 25 || dst[odd+3] != 0xFF {
+		t.Errorf("Odd row: expected half brightness, got %v", dst[odd:odd+4])
+	}
+}
+
+// TestPhosphorFilter tests the 1-2-1 horizontal blur
+func TestPhosphorFilter(t *testing.T) {
+	src := makeFrame(3, 1, 0, 0, 0)
+	src[4] = 200 // Middle pixel red
+
+	dst := make([]byte, len(src))
+	(&PhosphorFilter{}).Apply(dst, src, 3, 1, 12)
+
+	if dst[0] != 50 || dst[4] != 100 || dst[8] != 50 {
+		t.Errorf("Blur: expected 50/100/50, got %d/%d/%d", dst[0], dst[4], dst[8])
+	}
+}
+
+// TestNTSCFilter_GrayUnchanged tests that flat gray has no chroma to bleed
+func TestNTSCFilter_GrayUnchanged(t *testing.T) {
+	src := makeFrame(8, 2, 128, 128, 128)
+	dst := make([]byte, len(src))
+	(&NTSCFilter{}).Apply(dst, src, 8, 2, 32)
+
+	for i := 0; i < len(dst); i += 4 {
+		for c := 0; c < 3; c++ {
+			if d := int(dst[i+c]) - 128; d < -1 || d > 1 {
+				t.Fatalf("Pixel %d channel %d: expected ~128, got %d", i/4, c, dst[i+c])
+			}
+		}
+	}
+}
+
+// TestNTSCFilter_ChromaBleed tests that a color edge bleeds into neighbors
+func TestNTSCFilter_ChromaBleed(t *testing.T) {
+	src := makeFrame(8, 1, 0, 0, 0)
+	for x := 4; x < 8; x++ {
+		src[x*4] = 255 // Right half red
+	}
+	dst := make([]byte, len(src))
+	(&NTSCFilter{}).Apply(dst, src, 8, 1, 32)
+
+	if dst[3*4] == 0 {
+		t.Error("Pixel 3 next to the red edge should pick up color")
+	}
+	if dst[0] != 0 {
+		t.Errorf("Pixel 0 far from the edge should stay black, got %d", dst[0])
+	}
+}
+
+// TestNewVideoFilter tests built-in filter lookup
+func TestNewVideoFilter(t *testing.T) {
+	if NewVideoFilter(FilterNone) != nil {
+		t.Error("none should return nil")
+	}
+	if NewVideoFilter("bogus") != nil {
+		t.Error("unknown name should return nil")
+	}
+	for _, name := range []string{FilterScanlines, FilterPhosphor, FilterNTSC} {
+		if NewVideoFilter(name) == nil {
+			t.Errorf("%s should return a filter", name)
+		}
+	}
+}
+
+// TestEmulator_VideoFilterOption tests that filters apply to GetFramebuffer
+// without modifying the VDP framebuffer
+func TestEmulator_VideoFilterOption(t *testing.T) {
+	e := createTestEmulator()
+	pix := e.vdp.framebuffer.Pix
+	stride := e.vdp.framebuffer.Stride
+	for i := range pix {
+		pix[i] = 200
+	}
+
+	e.SetOption("video_filter", FilterScanlines)
+	fb := e.GetFramebuffer()
+	if len(fb) != stride*e.GetActiveHeight() {
+		t.Fatalf("Filtered length: expected %d, got %d", stride*e.GetActiveHeight(), len(fb))
+	}
+	if fb[stride] != 100 {
+		t.Errorf("Odd row should be darkened, got %d", fb[stride])
+	}
+	if pix[stride] != 200 {
+		t.Errorf("VDP framebuffer should be untouched, got %d", pix[stride])
+	}
+
+	e.SetOption("video_filter", FilterNone)
+	if fb := e.GetFramebuffer(); fb[stride] != 200 {
+		t.Errorf("Filter disabled: expected 200, got %d", fb[stride])
+	}
+}
+
+// TestFrameBlendFilter tests averaging with the previous frame
+func TestFrameBlendFilter(t *testing.T) {
+	f := &FrameBlendFilter{}
+	src := []byte{200, 100, 0, 255}
+	dst := make([]byte, 4)
+
+	f.Apply(dst, src, 1, 1, 4)
+	if dst[0] != 200 || dst[1] != 100 {
+		t.Errorf("First frame should pass through, got %v", dst)
+	}
+
+	src = []byte{0, 100, 201, 255}
+	f.Apply(dst, src, 1, 1, 4)
+	expected := []byte{100, 100, 101, 255}
+	for i := range expected {
+		if dst[i] != expected[i] {
+			t.Errorf("Blended byte %d: expected %d, got %d", i, expected[i], dst[i])
+		}
+	}
+
+	// Geometry change restarts blending
+	src = make([]byte, 8)
+	dst = make([]byte, 8)
+	f.Apply(dst, src, 2, 1, 8)
+	if dst[0] != 0 {
+		t.Errorf("After resize should pass through, got %d", dst[0])
+	}
+}
+
+// TestEmulator_FrameBlendOption tests that blending combines with the
+// selected filter
+func TestEmulator_FrameBlendOption(t *testing.T) {
+	e := createTestEmulator()
+	e.SetOption("video_filter", FilterScanlines)
+	e.SetOption("frame_blend", "true")
+	if len(e.videoFilters) != 2 {
+		t.Fatalf("Expected 2 filters, got %d", len(e.videoFilters))
+	}
+	if _, ok := e.videoFilters[0].(*FrameBlendFilter); !ok {
+		t.Errorf("Frame blend should run first, got %T", e.videoFilters[0])
+	}
+
+	e.SetOption("frame_blend", "false")
+	if len(e.videoFilters) != 1 {
+		t.Errorf("Expected 1 filter after disabling blend, got %d", len(e.videoFilters))
+	}
+}
+
+// TestEmulator_FrameBlendUnfetched tests that frame blending mixes
+// consecutive emulated frames when the frontend does not fetch each one
+func TestEmulator_FrameBlendUnfetched(t *testing.T) {
+	e := createTestEmulator()
+	e.SetOption("frame_blend", "true")
+
+	writeCRAM(e.vdp, 16, 0x03) // Backdrop red
+	e.RunFrame()
+	writeCRAM(e.vdp, 16, 0x0C) // Green
+	e.RunFrame()
+	writeCRAM(e.vdp, 16, 0x30) // Blue
+	e.RunFrame()
+
+	fb := e.GetFramebuffer()
+	if fb[0] != 0 || fb[1] != 128 || fb[2] != 128 {
+		t.Errorf("Expected green and blue blended to (0, 128, 128), got (%d, %d, %d)", fb[0], fb[1], fb[2])
+	}
+}
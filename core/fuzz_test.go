@@ -0,0 +1,71 @@
+package core
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+)
+
+// fuzzSeedStates returns valid v2 and v1 states to seed state fuzzing
+func fuzzSeedStates(f *testing.F) {
+	e := createTestEmulator()
+	e.RunFrame()
+	state, err := e.Serialize()
+	if err != nil {
+		f.Fatalf("Serialize failed: %v", err)
+	}
+	f.Add(state)
+	f.Add(toV1State(state))
+	f.Add(state[:stateHeaderSize])
+	f.Add([]byte{})
+}
+
+// fixStateCRC rewrites the data CRC so fuzzed payloads get past the
+// checksum and reach the chunk and section parsers
+func fixStateCRC(data []byte) []byte {
+	if len(data) < stateHeaderSize {
+		return data
+	}
+	fixed := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(fixed[18:22], crc32.ChecksumIEEE(fixed[stateHeaderSize:]))
+	return fixed
+}
+
+// FuzzDeserialize checks that corrupt save states return an error rather
+// than panic
+func FuzzDeserialize(f *testing.F) {
+	fuzzSeedStates(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		e := createTestEmulator()
+		e.Deserialize(data)
+		e.Deserialize(fixStateCRC(data))
+		// A state that loads must leave a runnable emulator
+		e.RunFrame()
+	})
+}
+
+// FuzzVerifyState checks that VerifyState never panics
+func FuzzVerifyState(f *testing.F) {
+	fuzzSeedStates(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		e := createTestEmulator()
+		e.VerifyState(data)
+		e.VerifyState(fixStateCRC(data))
+	})
+}
+
+// FuzzNewEmulator checks that malformed ROM images of any size can be
+// loaded and run without panicking
+func FuzzNewEmulator(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x00})
+	f.Add(createTestROM(1)[:0x2001])
+	f.Add(createTestROM(4))
+	f.Fuzz(func(t *testing.T, rom []byte) {
+		e, err := NewEmulator(rom)
+		if err != nil {
+			return
+		}
+		e.RunFrame()
+	})
+}
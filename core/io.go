@@ -1,6 +1,10 @@
 package core
 
-import "github.com/user-none/go-chip-sn76489"
+import (
+	"io"
+
+	"github.com/user-none/go-chip-sn76489"
+)
 
 // Input holds controller state (directly usable as port values)
 type Input struct {
@@ -11,15 +15,19 @@ type Input struct {
 type SMSIO struct {
 	vdp         *VDP
 	psg         *sn76489.SN76489
+	mixer       psgMixer // Per-channel volume applied to PSG writes
 	Input       *Input
 	nationality Nationality
 	ioControl   uint8 // Port $3F: I/O port control register
+
+	debugConsole io.Writer // SDSC debug console output, nil when disabled
 }
 
 func NewSMSIO(vdp *VDP, psg *sn76489.SN76489, nationality Nationality) *SMSIO {
 	return &SMSIO{
-		vdp: vdp,
-		psg: psg,
+		vdp:   vdp,
+		psg:   psg,
+		mixer: newPSGMixer(psg),
 		Input: &Input{
 			Port1: 0xFF, // All buttons released (active low)
 			Port2: 0xFF,
@@ -53,16 +61,60 @@ func (e *SMSIO) Out(addr uint8, value uint8) {
 	// SMS uses partial address decoding
 	switch addr & 0xC1 {
 	case 0x01: // $00-$3F odd: I/O port control register
-		e.ioControl = value
+		e.writeIOControl(value)
 	case 0x40, 0x41: // $40-$7F: PSG
 		if e.psg != nil {
-			e.psg.Write(value)
+			e.mixer.write(value)
 		}
 	case 0x80: // $80-$BF even: VDP data
 		e.vdp.WriteData(value)
 	case 0x81: // $80-$BF odd: VDP control
 		e.vdp.WriteControl(value)
+	case 0xC0, 0xC1: // $C0-$FF: no effect on hardware
+		e.writeDebugConsole(addr, value)
+	}
+}
+
+// ioWriteCycle is how far into an OUT instruction the I/O write lands.
+// OUT (n),A and OUT (C),r both start their I/O cycle 8-9 T-states in.
+const ioWriteCycle = 8
+
+// thLevels returns the TH line levels of ports A and B as bits 0 and 1.
+// A TH pin set as an input is pulled high.
+func thLevels(ioControl uint8) uint8 {
+	var levels uint8
+	if ioControl&0x02 != 0 || ioControl&0x20 != 0 {
+		levels |= 1
 	}
+	if ioControl&0x08 != 0 || ioControl&0x80 != 0 {
+		levels |= 2
+	}
+	return levels
+}
+
+// writeIOControl updates the I/O control register. A TH line falling
+// latches the H counter at the cycle of the write, the same edge a light
+// gun produces when it sees the beam. The latch holds until TH returns
+// high.
+func (e *SMSIO) writeIOControl(value uint8) {
+	before, after := thLevels(e.ioControl), thLevels(value)
+	e.ioControl = value
+	switch {
+	case before&^after != 0:
+		e.vdp.LatchHCounter(e.vdp.lineCycle + ioWriteCycle)
+	case after&^before != 0:
+		e.vdp.ReleaseHCounter()
+	}
+}
+
+// writeDebugConsole handles the SDSC debug console used by homebrew and
+// test ROMs: characters written to $FD are printed, $FC takes control
+// commands. Only text output is supported; commands are ignored.
+func (e *SMSIO) writeDebugConsole(addr uint8, value uint8) {
+	if e.debugConsole == nil || addr != 0xFD {
+		return
+	}
+	e.debugConsole.Write([]byte{value})
 }
 
 // UpdateInput updates controller state from button flags
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/user-none/go-chip-sn76489"
@@ -493,3 +494,67 @@ func TestIO_RegionDetection_Sequence(t *testing.T) {
 		}
 	})
 }
+
+// TestIO_DebugConsole tests SDSC debug console output on port $FD
+func TestIO_DebugConsole(t *testing.T) {
+	vdp := NewVDP()
+	psg := sn76489.New(3579545, 48000, 800, sn76489.Sega)
+	io := NewSMSIO(vdp, psg, NationalityExport)
+
+	// Disabled by default; writes must not panic
+	io.Out(0xFD, 'x')
+
+	var out strings.Builder
+	io.debugConsole = &out
+	for _, c := range []byte("OK\n") {
+		io.Out(0xFD, c)
+	}
+	io.Out(0xFC, 0x02) // Clear screen command, ignored
+	io.Out(0xC1, 'y')  // Other mirrors of port B are not the console
+
+	if got := out.String(); got != "OK\n" {
+		t.Errorf("Console output: expected %q, got %q", "OK\n", got)
+	}
+}
+
+// TestIO_HCounterLatchOnTH tests that a falling TH line latches the H
+// counter at the cycle of the write and a rising one releases it
+func TestIO_HCounterLatchOnTH(t *testing.T) {
+	vdp := NewVDP()
+	psg := sn76489.New(3579545, 48000, 800, sn76489.Sega)
+	io := NewSMSIO(vdp, psg, NationalityExport)
+
+	// TH A as a high output, then lowered at cycle 40 of the line
+	io.Out(0x3F, 0xF5)
+	vdp.SetLineCycle(40)
+	vdp.SetHCounter(0x10)
+	io.Out(0x3F, 0xD5)
+
+	want := GetHCounterForCycle(40 + ioWriteCycle)
+	vdp.SetHCounter(0x70)
+	if got := io.In(0x7F); got != want {
+		t.Errorf("Latched H counter: expected 0x%02X, got 0x%02X", want, got)
+	}
+
+	// Writes that keep TH low leave the latch alone
+	vdp.SetLineCycle(100)
+	io.Out(0x3F, 0xD5)
+	if got := io.In(0x7F); got != want {
+		t.Errorf("H counter after repeated write: expected 0x%02X, got 0x%02X", want, got)
+	}
+
+	// TH rising returns reads to the running counter. A TH pin switched
+	// to input is pulled high.
+	io.Out(0x3F, 0xFF)
+	if got := io.In(0x7F); got != 0x70 {
+		t.Errorf("H counter after release: expected 0x70, got 0x%02X", got)
+	}
+
+	// Port B TH latches too, wrapping into the next line
+	vdp.SetLineCycle(220)
+	io.Out(0x3F, 0x77)
+	want = GetHCounterForCycle((220 + ioWriteCycle) % 228)
+	if got := io.In(0x7F); got != want {
+		t.Errorf("H counter latched past line end: expected 0x%02X, got 0x%02X", want, got)
+	}
+}
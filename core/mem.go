@@ -19,6 +19,10 @@ type Memory struct {
 	ramControl uint8         // $FFFC: RAM mapping control (Sega mapper only)
 	bankMask   uint8         // Mask for valid bank numbers (based on ROM size)
 	mapper     MapperType    // Which mapper this ROM uses
+
+	cartRAMWritten bool // Set on any cartridge RAM write; cleared by the emulator
+	cartRAMUsed    int  // Highest cartridge RAM offset written, plus one
+	knownSRAMSize  int  // Battery RAM size from the ROM database, 0 if unknown
 }
 
 func NewMemory(rom []byte) *Memory {
@@ -40,8 +44,17 @@ func NewMemory(rom []byte) *Memory {
 	}
 	m.bankMask = uint8(pow2 - 1)
 
-	// Detect mapper type
-	m.mapper = detectMapper(rom)
+	m.SetMapper(detectMapper(rom))
+	m.knownSRAMSize = romSRAMSizes[crc32.ChecksumIEEE(rom)]
+	return m
+}
+
+// SetMapper selects the cartridge mapper and resets banking to that
+// mapper's power-on state. It is meant to be used before the game starts
+// running, to override the detected mapper for unlisted dumps.
+func (m *Memory) SetMapper(mapper MapperType) {
+	m.mapper = mapper
+	m.ramControl = 0
 
 	// Default bank mapping depends on mapper type
 	// Sega mapper: slots map to banks 0, 1, 2
@@ -53,19 +66,62 @@ func NewMemory(rom []byte) *Memory {
 	} else {
 		m.bankSlot[2] = 2
 	}
+}
 
-	return m
+// Mapper returns the active mapper
+func (m *Memory) Mapper() MapperType {
+	return m.mapper
 }
 
-// detectMapper identifies the mapper type based on ROM CRC32.
+// detectMapper identifies the mapper type based on ROM CRC32, falling back
+// to heuristics for ROMs missing from the database.
 func detectMapper(rom []byte) MapperType {
 	crc := crc32.ChecksumIEEE(rom)
 	if info, ok := romDatabase[crc]; ok {
 		return info.Mapper
 	}
+	return guessMapper(rom)
+}
+
+// guessMapper picks a mapper for an unlisted ROM. Codemasters cartridges
+// carry their own header at $7FE0 with a checksum and its complement;
+// failing that, the code is scanned for absolute stores to each mapper's
+// bank registers.
+func guessMapper(rom []byte) MapperType {
+	if hasCodemastersHeader(rom) {
+		return MapperCodemasters
+	}
+
+	// LD (nnnn),A to $0000/$4000/$8000 versus $FFFC-$FFFF
+	codemasters, sega := 0, 0
+	for i := 0; i+2 < len(rom); i++ {
+		if rom[i] != 0x32 {
+			continue
+		}
+		switch addr := uint16(rom[i+1]) | uint16(rom[i+2])<<8; addr {
+		case 0x0000, 0x4000, 0x8000:
+			codemasters++
+		case 0xFFFC, 0xFFFD, 0xFFFE, 0xFFFF:
+			sega++
+		}
+	}
+	if codemasters > sega && codemasters >= 2 {
+		return MapperCodemasters
+	}
 	return MapperSega
 }
 
+// hasCodemastersHeader reports whether the ROM has a Codemasters header:
+// a 16-bit checksum at $7FE6 and its two's complement at $7FE8.
+func hasCodemastersHeader(rom []byte) bool {
+	if len(rom) < 0x8000 {
+		return false
+	}
+	sum := uint16(rom[0x7FE6]) | uint16(rom[0x7FE7])<<8
+	inverse := uint16(rom[0x7FE8]) | uint16(rom[0x7FE9])<<8
+	return sum != 0 && sum+inverse == 0
+}
+
 // Get reads a byte from memory, dispatching to the appropriate mapper
 func (m *Memory) Get(addr uint16) uint8 {
 	switch m.mapper {
@@ -157,6 +213,10 @@ func (m *Memory) setSegaMapper(addr uint16, val uint8) {
 			ramBank := uint32((m.ramControl >> 2) & 0x01)
 			ramAddr := ramBank*0x4000 + uint32(addr-0x8000)
 			m.cartRAM[ramAddr] = val
+			m.cartRAMWritten = true
+			if int(ramAddr) >= m.cartRAMUsed {
+				m.cartRAMUsed = int(ramAddr) + 1
+			}
 		}
 
 	default:
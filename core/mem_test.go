@@ -538,6 +538,37 @@ func TestMemory_MapperDetectionSega(t *testing.T) {
 	}
 }
 
+// TestMemory_MapperHeuristicHeader tests detecting an unlisted
+// Codemasters ROM from its header checksum pair
+func TestMemory_MapperHeuristicHeader(t *testing.T) {
+	rom := createTestROM(8)
+	rom[0x7FE6], rom[0x7FE7] = 0x34, 0x12 // Checksum $1234
+	rom[0x7FE8], rom[0x7FE9] = 0xCC, 0xED // $10000 - $1234
+
+	if got := NewMemory(rom).Mapper(); got != MapperCodemasters {
+		t.Errorf("ROM with Codemasters header: expected Codemasters, got %v", got)
+	}
+
+	rom[0x7FE8] = 0xCD
+	if got := NewMemory(rom).Mapper(); got != MapperSega {
+		t.Errorf("Mismatched checksum pair: expected Sega, got %v", got)
+	}
+}
+
+// TestMemory_MapperHeuristicCode tests detection from bank register stores
+func TestMemory_MapperHeuristicCode(t *testing.T) {
+	rom := createTestROM(8)
+	copy(rom[0x100:], []byte{0x32, 0x00, 0x80, 0x32, 0x00, 0x40, 0x32, 0x00, 0x80})
+	if got := NewMemory(rom).Mapper(); got != MapperCodemasters {
+		t.Errorf("Stores to $4000/$8000: expected Codemasters, got %v", got)
+	}
+
+	copy(rom[0x200:], []byte{0x32, 0xFF, 0xFF, 0x32, 0xFE, 0xFF, 0x32, 0xFD, 0xFF, 0x32, 0xFC, 0xFF})
+	if got := NewMemory(rom).Mapper(); got != MapperSega {
+		t.Errorf("More stores to $FFFC-$FFFF: expected Sega, got %v", got)
+	}
+}
+
 // TestMemory_CodemastersOutOfBoundsRead tests reading beyond ROM size
 func TestMemory_CodemastersOutOfBoundsRead(t *testing.T) {
 	rom := createCodemastersTestROM(2) // Only 2 banks (32KB)
@@ -0,0 +1,100 @@
+package core
+
+import "image"
+
+// Overscan geometry in pixels. The horizontal borders come from the
+// H-counter regions (13 left border pixels at 329-341, 15 right border
+// pixels at 256-270). The frame height is constant per video standard
+// because the top and bottom borders shrink as the active area grows.
+const (
+	OverscanLeft      = 13
+	OverscanRight     = 15
+	OverscanWidth     = OverscanLeft + ScreenWidth + OverscanRight
+	OverscanNTSCLines = 243
+	OverscanPALLines  = 294
+	MaxOverscanHeight = OverscanPALLines
+)
+
+// overscanBorders returns the visible top and bottom border heights for
+// the given standard and active height, per the VDP frame structure tables.
+func overscanBorders(videoStd VideoStandard, activeHeight int) (top, bottom int) {
+	if videoStd == VideoPAL {
+		switch activeHeight {
+		case 224:
+			return 38, 32
+		case 240:
+			return 30, 24
+		default:
+			return 54, 48
+		}
+	}
+	switch activeHeight {
+	case 224:
+		return 11, 8
+	case 240:
+		// Not displayable on NTSC; ActiveHeight never reports 240 there
+		return 2, 1
+	default:
+		return 27, 24
+	}
+}
+
+// overscanHeight returns the full frame height including borders.
+func overscanHeight(videoStd VideoStandard) int {
+	if videoStd == VideoPAL {
+		return OverscanPALLines
+	}
+	return OverscanNTSCLines
+}
+
+// newOverscanBuffer allocates a framebuffer large enough for either standard.
+func newOverscanBuffer() *image.RGBA {
+	return image.NewRGBA(image.Rect(0, 0, OverscanWidth, MaxOverscanHeight))
+}
+
+// renderOverscanLine composes one scanline of the bordered frame. Active
+// lines are copied from the VDP framebuffer with backdrop-colored side
+// borders; border lines are filled with the backdrop color latched for that
+// line so raster effects on register 7 are visible. Blanking and sync lines
+// are not part of the visible frame and are skipped.
+//
+// The top border is drawn at the end of the frame, after the bottom border,
+// because that is when the VDP outputs it.
+func (e *Emulator) renderOverscanLine(line, activeHeight int) {
+	top, bottom := overscanBorders(e.videoStd, activeHeight)
+
+	var row int
+	switch {
+	case line < activeHeight+bottom:
+		row = top + line
+	case line >= e.scanlines-top:
+		row = line - (e.scanlines - top)
+	default:
+		return
+	}
+
+	bg := e.vdp.BackdropColor()
+	dst := e.overscanBuffer.Pix
+	dstOff := row * e.overscanBuffer.Stride
+
+	fill := func(from, to int) {
+		for x := from; x < to; x++ {
+			p := dstOff + x*4
+			dst[p] = bg.R
+			dst[p+1] = bg.G
+			dst[p+2] = bg.B
+			dst[p+3] = 0xFF
+		}
+	}
+
+	if line >= activeHeight {
+		fill(0, OverscanWidth)
+		return
+	}
+
+	fill(0, OverscanLeft)
+	srcStride := e.vdp.framebuffer.Stride
+	srcOff := line * srcStride
+	copy(dst[dstOff+OverscanLeft*4:], e.vdp.framebuffer.Pix[srcOff:srcOff+ScreenWidth*4])
+	fill(OverscanLeft+ScreenWidth, OverscanWidth)
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"errors"
+	"image/color"
+	"math"
+	"os"
+)
+
+// Palette maps each of the 64 possible SMS colors (6-bit CRAM value,
+// --BBGGRR) to the RGBA color shown on screen.
+type Palette [64]color.RGBA
+
+// Built-in palette names accepted by the palette option
+const (
+	PaletteNameOriginal = "original"
+	PaletteNameContrast = "contrast"
+)
+
+// palFileSize is the size of a raw .pal file: 64 entries of R, G, B bytes
+const palFileSize = 64 * 3
+
+// paletteFromLevels builds a palette from the output level of each 2-bit
+// color component.
+func paletteFromLevels(levels [4]uint8) Palette {
+	var p Palette
+	for c := range p {
+		p[c] = color.RGBA{
+			R: levels[(c>>0)&0x03],
+			G: levels[(c>>2)&0x03],
+			B: levels[(c>>4)&0x03],
+			A: 255,
+		}
+	}
+	return p
+}
+
+// OriginalPalette returns the linear palette: each 2-bit component scaled
+// evenly to 0, 85, 170, 255.
+func OriginalPalette() Palette {
+	return paletteFromLevels([4]uint8{0, 85, 170, 255})
+}
+
+// ContrastPalette returns a gamma-corrected palette (gamma 1.25) with
+// darker mid levels, closer to how the SMS looks on a CRT.
+func ContrastPalette() Palette {
+	var levels [4]uint8
+	for i := range levels {
+		levels[i] = uint8(math.Round(255 * math.Pow(float64(i)/3, 1.25)))
+	}
+	return paletteFromLevels(levels)
+}
+
+// ParsePalette decodes a raw .pal file: 64 consecutive R, G, B byte
+// triplets indexed by SMS color value.
+func ParsePalette(data []byte) (Palette, error) {
+	var p Palette
+	if len(data) < palFileSize {
+		return p, errors.New("palette file too short: need 192 bytes")
+	}
+	for c := range p {
+		p[c] = color.RGBA{
+			R: data[c*3],
+			G: data[c*3+1],
+			B: data[c*3+2],
+			A: 255,
+		}
+	}
+	return p, nil
+}
+
+// LoadPalette reads and decodes a .pal file from disk.
+func LoadPalette(path string) (Palette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Palette{}, err
+	}
+	return ParsePalette(data)
+}
+
+// PaletteByName returns a built-in palette, or loads name as a .pal file
+// path if it is not a built-in.
+func PaletteByName(name string) (Palette, error) {
+	switch name {
+	case "", PaletteNameOriginal:
+		return OriginalPalette(), nil
+	case PaletteNameContrast:
+		return ContrastPalette(), nil
+	default:
+		return LoadPalette(name)
+	}
+}
+
+// SetPalette replaces the color palette used for rendering.
+func (v *VDP) SetPalette(p Palette) {
+	v.palette = p
+	v.updatePalette565()
+}
+
+// SetPalette replaces the color palette used for rendering.
+func (e *Emulator) SetPalette(p Palette) {
+	e.vdp.SetPalette(p)
+	e.filterValid = false
+}
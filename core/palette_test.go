@@ -0,0 +1,112 @@
+package core
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestOriginalPalette tests the linear palette matches the 2-bit scale
+func TestOriginalPalette(t *testing.T) {
+	p := OriginalPalette()
+
+	testCases := []struct {
+		value    uint8
+		expected color.RGBA
+	}{
+		{0x00, color.RGBA{R: 0, G: 0, B: 0, A: 255}},
+		{0x03, color.RGBA{R: 255, G: 0, B: 0, A: 255}},
+		{0x0C, color.RGBA{R: 0, G: 255, B: 0, A: 255}},
+		{0x30, color.RGBA{R: 0, G: 0, B: 255, A: 255}},
+		{0x15, color.RGBA{R: 85, G: 85, B: 85, A: 255}},
+		{0x2A, color.RGBA{R: 170, G: 170, B: 170, A: 255}},
+	}
+	for _, tc := range testCases {
+		if got := p[tc.value]; got != tc.expected {
+			t.Errorf("Color 0x%02X: expected %v, got %v", tc.value, tc.expected, got)
+		}
+	}
+}
+
+// TestContrastPalette tests that mid levels are darker and extremes kept
+func TestContrastPalette(t *testing.T) {
+	p := ContrastPalette()
+	orig := OriginalPalette()
+
+	if p[0x00] != orig[0x00] || p[0x3F] != orig[0x3F] {
+		t.Errorf("Black/white should match the original palette")
+	}
+	if p[0x15].R >= orig[0x15].R || p[0x2A].R >= orig[0x2A].R {
+		t.Errorf("Mid levels should be darker: got %d/%d", p[0x15].R, p[0x2A].R)
+	}
+}
+
+// TestParsePalette tests .pal decoding and size validation
+func TestParsePalette(t *testing.T) {
+	if _, err := ParsePalette(make([]byte, 100)); err == nil {
+		t.Error("Short palette should return an error")
+	}
+
+	data := make([]byte, palFileSize)
+	data[5*3] = 10
+	data[5*3+1] = 20
+	data[5*3+2] = 30
+	p, err := ParsePalette(data)
+	if err != nil {
+		t.Fatalf("ParsePalette: %v", err)
+	}
+	if want := (color.RGBA{R: 10, G: 20, B: 30, A: 255}); p[5] != want {
+		t.Errorf("Entry 5: expected %v, got %v", want, p[5])
+	}
+}
+
+// TestVDP_SetPalette tests that rendering uses the selected palette
+func TestVDP_SetPalette(t *testing.T) {
+	vdp := NewVDP()
+
+	var custom Palette
+	custom[0x03] = color.RGBA{R: 1, G: 2, B: 3, A: 255}
+	vdp.SetPalette(custom)
+
+	writeCRAM(vdp, 0, 0x03)
+	vdp.LatchCRAM()
+
+	if got := vdp.cramToColor(0); got != custom[0x03] {
+		t.Errorf("cramToColor with custom palette: expected %v, got %v", custom[0x03], got)
+	}
+}
+
+// TestEmulator_PaletteOption tests selecting palettes by name and file
+func TestEmulator_PaletteOption(t *testing.T) {
+	e := createTestEmulator()
+
+	e.SetOption("palette", PaletteNameContrast)
+	if e.vdp.palette != ContrastPalette() {
+		t.Error("contrast option should select the contrast palette")
+	}
+
+	data := make([]byte, palFileSize)
+	for i := range data {
+		data[i] = 7
+	}
+	path := filepath.Join(t.TempDir(), "test.pal")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	e.SetOption("palette", path)
+	if e.vdp.palette[0x3F].R != 7 {
+		t.Errorf("File palette not applied, got %v", e.vdp.palette[0x3F])
+	}
+
+	// Missing file keeps the current palette
+	e.SetOption("palette", filepath.Join(t.TempDir(), "missing.pal"))
+	if e.vdp.palette[0x3F].R != 7 {
+		t.Error("Failed load should keep the previous palette")
+	}
+
+	e.SetOption("palette", PaletteNameOriginal)
+	if e.vdp.palette != OriginalPalette() {
+		t.Error("original option should restore the linear palette")
+	}
+}
@@ -0,0 +1,141 @@
+package core
+
+import "image/color"
+
+// PixelFormat selects the layout of the frame returned by GetFramebuffer.
+type PixelFormat int
+
+const (
+	// PixelFormatRGBA8888 is 4 bytes per pixel in R, G, B, A order
+	PixelFormatRGBA8888 PixelFormat = iota
+	// PixelFormatRGB565 is 2 bytes per pixel, a little-endian uint16 with
+	// red in the top 5 bits, green in the middle 6 and blue in the low 5
+	PixelFormatRGB565
+	// PixelFormatXRGB8888 is 4 bytes per pixel, a little-endian uint32
+	// with red, green and blue in bits 16-23, 8-15 and 0-7
+	PixelFormatXRGB8888
+)
+
+// toRGB565 packs an RGBA color into 5:6:5
+func toRGB565(c color.RGBA) uint16 {
+	return uint16(c.R>>3)<<11 | uint16(c.G>>2)<<5 | uint16(c.B>>3)
+}
+
+// SetPixelFormat selects the render target. Formats other than RGBA are
+// rendered into a separate persistent buffer so frontends that want them
+// need no per-frame conversion. The RGBA framebuffer is not updated while
+// another format is selected.
+func (v *VDP) SetPixelFormat(f PixelFormat) {
+	v.format = f
+	if f != PixelFormatRGBA8888 && v.packedFramebuffer == nil {
+		v.packedFramebuffer = make([]byte, ScreenWidth*4*MaxScreenHeight)
+	}
+	v.updatePalette565()
+}
+
+// PixelFormat returns the current render target format
+func (v *VDP) PixelFormat() PixelFormat {
+	return v.format
+}
+
+// PixelBuffer returns the render target for the current pixel format.
+// Rows are ScreenWidth pixels with no padding.
+func (v *VDP) PixelBuffer() []byte {
+	if v.format == PixelFormatRGBA8888 {
+		return v.framebuffer.Pix
+	}
+	return v.packedFramebuffer[:ScreenWidth*v.bytesPerPixel()*MaxScreenHeight]
+}
+
+// rgbaOutput reports whether the renderer writes the RGBA framebuffer
+func (v *VDP) rgbaOutput() bool {
+	return v.format == PixelFormatRGBA8888
+}
+
+// updatePalette565 rebuilds the packed palette from the RGBA palette
+func (v *VDP) updatePalette565() {
+	for i, c := range v.palette {
+		v.palette565[i] = toRGB565(c)
+	}
+}
+
+// bytesPerPixel returns the size of one pixel in the render target
+func (v *VDP) bytesPerPixel() int {
+	if v.format == PixelFormatRGB565 {
+		return 2
+	}
+	return 4
+}
+
+// targetRow returns the render target bytes for one scanline
+func (v *VDP) targetRow(line uint16) []byte {
+	if v.format == PixelFormatRGBA8888 {
+		off := int(line) * v.framebuffer.Stride
+		return v.framebuffer.Pix[off : off+ScreenWidth*4]
+	}
+	rowBytes := ScreenWidth * v.bytesPerPixel()
+	off := int(line) * rowBytes
+	return v.packedFramebuffer[off : off+rowBytes]
+}
+
+// putPixel writes a 6-bit SMS color at column x of a render target row
+func (v *VDP) putPixel(row []byte, x int, c uint8) {
+	switch v.format {
+	case PixelFormatRGB565:
+		p := v.palette565[c&0x3F]
+		row[x*2] = uint8(p)
+		row[x*2+1] = uint8(p >> 8)
+	case PixelFormatXRGB8888:
+		rgba := v.palette[c&0x3F]
+		p := x * 4
+		row[p] = rgba.B
+		row[p+1] = rgba.G
+		row[p+2] = rgba.R
+		row[p+3] = 0xFF
+	default:
+		rgba := v.palette[c&0x3F]
+		p := x * 4
+		row[p] = rgba.R
+		row[p+1] = rgba.G
+		row[p+2] = rgba.B
+		row[p+3] = 0xFF
+	}
+}
+
+// SetPixelFormat selects the format of the frame returned by
+// GetFramebuffer and FrameView. Overscan and video filters operate on
+// RGBA frames and are bypassed while another format is selected.
+func (e *Emulator) SetPixelFormat(f PixelFormat) {
+	e.vdp.SetPixelFormat(f)
+	e.filterValid = false
+}
+
+// PixelFormat returns the format of the frame returned by GetFramebuffer
+func (e *Emulator) PixelFormat() PixelFormat {
+	return e.vdp.PixelFormat()
+}
+
+// FrameView returns the current frame without copying. pixels starts at
+// the first visible pixel and rows are pitch bytes apart, so with crop
+// border active the view is the render target offset by 8 pixels rather
+// than a compacted copy; width can no longer be derived from the pitch.
+// The buffer is persistent and overwritten by the next RunFrame.
+// Overscan and filtered frames are composed in a separate buffer and are
+// returned as GetFramebuffer would.
+func (e *Emulator) FrameView() (pixels []byte, width, height, pitch int) {
+	if e.overscanActive() || (len(e.videoFilters) > 0 && e.vdp.rgbaOutput()) {
+		pitch = e.GetFramebufferStride()
+		return e.GetFramebuffer(), pitch / 4, e.GetActiveHeight(), pitch
+	}
+
+	bpp := e.vdp.bytesPerPixel()
+	pitch = ScreenWidth * bpp
+	width = ScreenWidth
+	height = e.vdp.ActiveHeight()
+	pixels = e.vdp.PixelBuffer()
+	if e.cropBorder && e.vdp.LeftColumnBlankEnabled() {
+		pixels = pixels[8*bpp:]
+		width -= 8
+	}
+	return pixels[:pitch*(height-1)+width*bpp], width, height, pitch
+}
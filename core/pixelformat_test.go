@@ -0,0 +1,113 @@
+package core
+
+import "testing"
+
+// TestToRGB565 tests packing of the SMS color levels
+func TestToRGB565(t *testing.T) {
+	p := OriginalPalette()
+
+	testCases := []struct {
+		smsColor uint8
+		expected uint16
+	}{
+		{0x00, 0x0000},
+		{0x03, 0xF800}, // Red
+		{0x0C, 0x07E0}, // Green
+		{0x30, 0x001F}, // Blue
+		{0x3F, 0xFFFF}, // White
+	}
+	for _, tc := range testCases {
+		if got := toRGB565(p[tc.smsColor]); got != tc.expected {
+			t.Errorf("Color 0x%02X: expected 0x%04X, got 0x%04X", tc.smsColor, tc.expected, got)
+		}
+	}
+}
+
+// TestVDP_RGB565_MatchesRGBA tests that the RGB565 target renders the same
+// image as the RGBA framebuffer
+func TestVDP_RGB565_MatchesRGBA(t *testing.T) {
+	rgba := NewVDP()
+	goldenScene(rgba, 0x26, 0x42)
+	renderGoldenFrame(rgba)
+
+	vdp := NewVDP()
+	vdp.SetPixelFormat(PixelFormatRGB565)
+	goldenScene(vdp, 0x26, 0x42)
+	renderGoldenFrame(vdp)
+
+	fb := vdp.PixelBuffer()
+	for y := 0; y < vdp.ActiveHeight(); y++ {
+		for x := 0; x < ScreenWidth; x++ {
+			i := (y*ScreenWidth + x) * 2
+			got := uint16(fb[i]) | uint16(fb[i+1])<<8
+			expected := toRGB565(rgba.framebuffer.RGBAAt(x, y))
+			if got != expected {
+				t.Fatalf("Pixel (%d, %d): expected 0x%04X, got 0x%04X", x, y, expected, got)
+			}
+		}
+	}
+}
+
+// TestEmulator_RGB565_Crop tests frame size and stride in RGB565 mode
+func TestEmulator_RGB565_Crop(t *testing.T) {
+	e := createTestEmulator()
+	e.SetPixelFormat(PixelFormatRGB565)
+	e.vdp.register[1] = 0x40
+
+	if got := e.GetFramebufferStride(); got != ScreenWidth*2 {
+		t.Errorf("Stride: expected %d, got %d", ScreenWidth*2, got)
+	}
+
+	e.SetOption("crop_border", "true")
+	e.vdp.register[0] = 0x20 // Left column blank
+	e.RunFrame()
+
+	stride := e.GetFramebufferStride()
+	if stride != (ScreenWidth-8)*2 {
+		t.Errorf("Cropped stride: expected %d, got %d", (ScreenWidth-8)*2, stride)
+	}
+	if got := len(e.GetFramebuffer()); got != stride*e.GetActiveHeight() {
+		t.Errorf("Cropped frame: expected %d bytes, got %d", stride*e.GetActiveHeight(), got)
+	}
+}
+
+// TestVDP_XRGB8888 tests the byte order of the XRGB target
+func TestVDP_XRGB8888(t *testing.T) {
+	vdp := NewVDP()
+	vdp.SetPixelFormat(PixelFormatXRGB8888)
+	writeCRAM(vdp, 16, 0x03) // Backdrop red
+
+	vdp.SetVCounter(0)
+	vdp.LatchCRAM()
+	vdp.LatchPerLineRegisters()
+	vdp.RenderScanline()
+
+	pix := vdp.PixelBuffer()
+	expected := []byte{0x00, 0x00, 0xFF, 0xFF}
+	for i, b := range expected {
+		if pix[i] != b {
+			t.Fatalf("Byte %d: expected 0x%02X, got 0x%02X", i, b, pix[i])
+		}
+	}
+}
+
+// TestEmulator_FrameView_Crop tests that the cropped view aliases the
+// render target with the full pitch
+func TestEmulator_FrameView_Crop(t *testing.T) {
+	e := createTestEmulator()
+	e.SetPixelFormat(PixelFormatXRGB8888)
+	e.SetOption("crop_border", "true")
+	e.vdp.register[0] = 0x20 // Left column blank
+
+	pixels, width, height, pitch := e.FrameView()
+	if width != ScreenWidth-8 || height != 192 || pitch != ScreenWidth*4 {
+		t.Fatalf("View: expected %dx192 pitch %d, got %dx%d pitch %d",
+			ScreenWidth-8, ScreenWidth*4, width, height, pitch)
+	}
+	if &pixels[0] != &e.vdp.PixelBuffer()[8*4] {
+		t.Errorf("Cropped view should alias the render target at pixel 8")
+	}
+	if len(pixels) != pitch*(height-1)+width*4 {
+		t.Errorf("View length: expected %d, got %d", pitch*(height-1)+width*4, len(pixels))
+	}
+}
@@ -251,3 +251,135 @@ func TestPSG_ToneLatchPersistence(t *testing.T) {
 		t.Errorf("After second data: expected 0x%03X, got 0x%03X", expected, got)
 	}
 }
+
+// TestPSGMixer_ChannelVolume tests that added attenuation follows the
+// game's volume writes and that muting and restoring are exact
+func TestPSGMixer_ChannelVolume(t *testing.T) {
+	psg := sn76489.New(3579545, 48000, 800, sn76489.Sega)
+	m := newPSGMixer(psg)
+
+	m.write(0x95) // Channel 0 volume 5
+	m.setVolume(0, 3)
+	if got := psg.GetVolume(0); got != 0x08 {
+		t.Errorf("After setVolume: expected 0x08, got 0x%02X", got)
+	}
+
+	m.write(0x92) // Game changes volume to 2
+	if got := psg.GetVolume(0); got != 0x05 {
+		t.Errorf("After game write: expected 0x05, got 0x%02X", got)
+	}
+
+	m.setVolume(0, 0x0F)
+	if got := psg.GetVolume(0); got != 0x0F {
+		t.Errorf("Muted: expected 0x0F, got 0x%02X", got)
+	}
+
+	m.setVolume(0, 0)
+	if got := psg.GetVolume(0); got != 0x02 {
+		t.Errorf("Restored: expected 0x02, got 0x%02X", got)
+	}
+
+	// Untouched channels stay silent
+	m.setVolume(1, 2)
+	if got := psg.GetVolume(1); got != 0x0F {
+		t.Errorf("Silent channel 1: expected 0x0F, got 0x%02X", got)
+	}
+}
+
+// TestPSGMixer_RestoresLatch tests that a data byte written after a
+// volume change still reaches the register the game latched
+func TestPSGMixer_RestoresLatch(t *testing.T) {
+	psg := sn76489.New(3579545, 48000, 800, sn76489.Sega)
+	m := newPSGMixer(psg)
+
+	m.write(0xA3) // Latch channel 1 tone, low nibble 3
+	m.setVolume(0, 4)
+	m.write(0x10) // High 6 bits of channel 1 tone
+
+	if got := psg.GetToneReg(1); got != 0x103 {
+		t.Errorf("Channel 1 tone: expected 0x103, got 0x%03X", got)
+	}
+	if got := psg.GetToneReg(0); got != 0 {
+		t.Errorf("Channel 0 tone should be untouched, got 0x%03X", got)
+	}
+}
+
+// TestPSGMixer_NoiseLatch tests that a volume change while the game has
+// the noise register latched leaves the noise shift register alone, and
+// that a following data byte still reaches the noise register
+func TestPSGMixer_NoiseLatch(t *testing.T) {
+	psg := sn76489.New(3579545, 48000, 800, sn76489.Sega)
+	m := newPSGMixer(psg)
+
+	m.write(0xF0) // Noise volume 0
+	m.write(0xE4) // Latch noise register: white noise, /512
+	psg.Run(4096)
+	shift := psg.GetNoiseShift()
+
+	m.setVolume(AudioChannelNoise, 2)
+	if got := psg.GetNoiseShift(); got != shift {
+		t.Errorf("Noise shift register changed by setVolume: 0x%04X -> 0x%04X", shift, got)
+	}
+	if got := psg.GetVolume(AudioChannelNoise); got != 0x02 {
+		t.Errorf("Noise volume: expected 0x02, got 0x%02X", got)
+	}
+
+	m.write(0x05) // Data byte for the latched noise register
+	if got := psg.GetNoiseReg(); got != 0x05 {
+		t.Errorf("Noise register: expected 0x05, got 0x%02X", got)
+	}
+	if got := psg.GetVolume(AudioChannelNoise); got != 0x02 {
+		t.Errorf("Noise volume changed by the data byte: got 0x%02X", got)
+	}
+}
+
+// TestVolumeToAttenuation tests percentage to 2 dB step conversion
+func TestVolumeToAttenuation(t *testing.T) {
+	testCases := []struct {
+		percent  int
+		expected uint8
+	}{
+		{100, 0},
+		{150, 0},
+		{50, 3}, // -6 dB
+		{25, 6}, // -12 dB
+		{1, 15},
+		{0, 15},
+		{-5, 15},
+	}
+	for _, tc := range testCases {
+		if got := volumeToAttenuation(tc.percent); got != tc.expected {
+			t.Errorf("volumeToAttenuation(%d): expected %d, got %d", tc.percent, tc.expected, got)
+		}
+	}
+}
+
+// TestAudioLowPass tests that the filter settles to a constant input and
+// attenuates a signal alternating every sample
+func TestAudioLowPass(t *testing.T) {
+	f := newAudioLowPass(48000)
+
+	var y float32
+	for i := 0; i < 1000; i++ {
+		y = f.process(1)
+	}
+	if math.Abs(float64(y-1)) > 1e-3 {
+		t.Errorf("DC: expected 1, got %f", y)
+	}
+
+	f.state = 0
+	var peak float32
+	for i := 0; i < 1000; i++ {
+		x := float32(1)
+		if i%2 == 1 {
+			x = -1
+		}
+		y = f.process(x)
+		if i > 900 && float32(math.Abs(float64(y))) > peak {
+			peak = float32(math.Abs(float64(y)))
+		}
+	}
+	if peak > 0.5 {
+		t.Errorf("Nyquist: expected strong attenuation, peak %f", peak)
+	}
+}
@@ -74,19 +74,8 @@ func (n Nationality) String() string {
 // DetectNationalityFromROM reads the ROM header to determine nationality.
 // Returns Export if the header is missing or unrecognizable.
 func DetectNationalityFromROM(rom []byte) Nationality {
-	// Header is at $7FF0; need at least $8000 bytes
-	if len(rom) < 0x8000 {
-		return NationalityExport
-	}
-
-	// Check for "TMR SEGA" signature at $7FF0
-	if string(rom[0x7FF0:0x7FF8]) != "TMR SEGA" {
-		return NationalityExport
-	}
-
-	// Region code is upper nibble of $7FFF
-	regionCode := rom[0x7FFF] >> 4
-	if regionCode == 3 { // SMS Japan
+	hdr, ok := ParseSegaHeader(rom)
+	if ok && hdr.RegionCode == SegaRegionSMSJapan {
 		return NationalityJapanese
 	}
 	return NationalityExport
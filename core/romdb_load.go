@@ -0,0 +1,106 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// romDatabaseEntry is one entry of an external ROM database file. Mapper
+// and video may be omitted to keep the built-in value, or the default
+// (Sega, NTSC) for ROMs the built-in table does not list.
+type romDatabaseEntry struct {
+	Name   string `json:"name,omitempty"` // For readers of the file only
+	CRC32  string `json:"crc32"`          // 8 hex digits
+	Mapper string `json:"mapper,omitempty"`
+	Video  string `json:"video,omitempty"`
+	SRAM   int    `json:"sram,omitempty"` // Battery RAM bytes: 8192, 16384 or 32768
+}
+
+// LoadROMDatabase merges a JSON array of entries over the built-in ROM
+// database and returns the number of entries applied:
+//
+//	[{"name": "Micro Machines", "crc32": "a577ce46", "mapper": "codemasters", "video": "pal"}]
+//
+// Mapper is "sega" or "codemasters"; video is "ntsc" or "pal". sram sets
+// the battery RAM size so saves are not padded to 32KB. The file
+// is validated as a whole, so a bad entry leaves the database unchanged.
+// It must be called before emulators are created.
+func LoadROMDatabase(r io.Reader) (int, error) {
+	var entries []romDatabaseEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return 0, fmt.Errorf("rom database: %w", err)
+	}
+
+	merged := make(map[uint32]ROMInfo, len(entries))
+	sramSizes := make(map[uint32]int)
+	for i, entry := range entries {
+		crc, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(entry.CRC32), "0x"), 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("rom database entry %d: bad crc32 %q", i, entry.CRC32)
+		}
+		info := romDatabase[uint32(crc)]
+		if entry.Mapper != "" {
+			if info.Mapper, err = ParseMapper(entry.Mapper); err != nil {
+				return 0, fmt.Errorf("rom database entry %d: %w", i, err)
+			}
+		}
+		if entry.Video != "" {
+			if info.VideoStd, err = ParseVideoStandard(entry.Video); err != nil {
+				return 0, fmt.Errorf("rom database entry %d: %w", i, err)
+			}
+		}
+		if entry.SRAM != 0 && !validSRAMSize(entry.SRAM) {
+			return 0, fmt.Errorf("rom database entry %d: bad sram size %d", i, entry.SRAM)
+		}
+		merged[uint32(crc)] = info
+		if entry.SRAM != 0 {
+			sramSizes[uint32(crc)] = entry.SRAM
+		}
+	}
+
+	for crc, info := range merged {
+		romDatabase[crc] = info
+	}
+	for crc, size := range sramSizes {
+		romSRAMSizes[crc] = size
+	}
+	return len(entries), nil
+}
+
+// LoadROMDatabaseFile merges a JSON ROM database file over the built-in
+// database. See LoadROMDatabase for the format.
+func LoadROMDatabaseFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return LoadROMDatabase(f)
+}
+
+// ParseMapper converts a mapper name ("sega" or "codemasters") to a
+// MapperType.
+func ParseMapper(name string) (MapperType, error) {
+	switch strings.ToLower(name) {
+	case "sega":
+		return MapperSega, nil
+	case "codemasters":
+		return MapperCodemasters, nil
+	}
+	return MapperSega, fmt.Errorf("unknown mapper %q", name)
+}
+
+// ParseVideoStandard converts "ntsc" or "pal" to a VideoStandard.
+func ParseVideoStandard(name string) (VideoStandard, error) {
+	switch strings.ToLower(name) {
+	case "ntsc":
+		return VideoNTSC, nil
+	case "pal":
+		return VideoPAL, nil
+	}
+	return VideoNTSC, fmt.Errorf("unknown video standard %q", name)
+}
@@ -1,7 +1,9 @@
 package core
 
 import (
+	"fmt"
 	"hash/crc32"
+	"strings"
 	"testing"
 )
 
@@ -164,3 +166,89 @@ func TestDetectVideoStandardFromROM_Integration(t *testing.T) {
 		t.Errorf("Default should be NTSC, got %v", videoStd)
 	}
 }
+
+// restoreROMDatabase snapshots the built-in database and restores it when
+// the test ends
+func restoreROMDatabase(t *testing.T) {
+	t.Helper()
+	saved := make(map[uint32]ROMInfo, len(romDatabase))
+	for k, v := range romDatabase {
+		saved[k] = v
+	}
+	t.Cleanup(func() { romDatabase = saved })
+}
+
+// TestLoadROMDatabase tests merging external entries over the table
+func TestLoadROMDatabase(t *testing.T) {
+	restoreROMDatabase(t)
+	rom := createTestROM(4)
+	crc := crc32.ChecksumIEEE(rom)
+
+	n, err := LoadROMDatabase(strings.NewReader(`[
+		{"name": "Test", "crc32": "` + fmt.Sprintf("%08X", crc) + `", "mapper": "codemasters", "video": "pal"},
+		{"crc32": "0xb519e833", "video": "pal"}
+	]`))
+	if err != nil {
+		t.Fatalf("LoadROMDatabase failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Entries applied: expected 2, got %d", n)
+	}
+
+	if got := detectMapper(rom); got != MapperCodemasters {
+		t.Errorf("New entry mapper: expected Codemasters, got %v", got)
+	}
+	if got, ok := DetectVideoStandardFromROM(rom); !ok || got != VideoPAL {
+		t.Errorf("New entry video: expected PAL, got %v (found=%v)", got, ok)
+	}
+
+	// Omitted fields keep the built-in value
+	sonic := romDatabase[0xb519e833]
+	if sonic.Mapper != MapperSega || sonic.VideoStd != VideoPAL {
+		t.Errorf("Merged entry: expected Sega/PAL, got %+v", sonic)
+	}
+}
+
+// TestLoadROMDatabase_Invalid tests that a bad file leaves the table unchanged
+func TestLoadROMDatabase_Invalid(t *testing.T) {
+	restoreROMDatabase(t)
+	before := len(romDatabase)
+
+	for _, data := range []string{
+		`not json`,
+		`[{"crc32": "12345678", "mapper": "sega"}, {"crc32": "xyz"}]`,
+		`[{"crc32": "12345678", "mapper": "korean"}]`,
+		`[{"crc32": "12345678", "video": "secam"}]`,
+	} {
+		if _, err := LoadROMDatabase(strings.NewReader(data)); err == nil {
+			t.Errorf("LoadROMDatabase(%q) should fail", data)
+		}
+	}
+	if len(romDatabase) != before {
+		t.Errorf("Failed loads changed the database: %d entries, expected %d", len(romDatabase), before)
+	}
+}
+
+// TestEmulator_MapperOption tests overriding the detected mapper
+func TestEmulator_MapperOption(t *testing.T) {
+	e := createTestEmulator()
+	if e.mem.Mapper() != MapperSega {
+		t.Fatalf("Detected mapper: expected Sega, got %v", e.mem.Mapper())
+	}
+
+	e.SetOption("mapper", "codemasters")
+	if e.mem.Mapper() != MapperCodemasters {
+		t.Errorf("Override: expected Codemasters, got %v", e.mem.Mapper())
+	}
+	if e.mem.GetBankSlot(2) != 0 {
+		t.Errorf("Codemasters slot 2: expected bank 0, got %d", e.mem.GetBankSlot(2))
+	}
+
+	e.SetOption("mapper", "auto")
+	if e.mem.Mapper() != MapperSega {
+		t.Errorf("Auto: expected Sega, got %v", e.mem.Mapper())
+	}
+	if e.mem.GetBankSlot(2) != 2 {
+		t.Errorf("Sega slot 2: expected bank 2, got %d", e.mem.GetBankSlot(2))
+	}
+}
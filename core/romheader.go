@@ -0,0 +1,165 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Sega header location and region codes
+const (
+	segaHeaderAddr = 0x7FF0
+
+	SegaRegionSMSJapan  = 3
+	SegaRegionSMSExport = 4
+	SegaRegionGGJapan   = 5
+	SegaRegionGGExport  = 6
+	SegaRegionGGIntl    = 7
+)
+
+// segaROMSizes maps the header size code to the number of bytes the BIOS
+// checksums. Sizes up to 48KB stop short of their last 16 bytes, where the
+// header sits in smaller ROMs.
+var segaROMSizes = map[uint8]int{
+	0xA: 0x1FF0,
+	0xB: 0x3FF0,
+	0xC: 0x7FF0,
+	0xD: 0xBFF0,
+	0xE: 0x10000,
+	0xF: 0x20000,
+	0x0: 0x40000,
+	0x1: 0x80000,
+	0x2: 0x100000,
+}
+
+// SegaHeader holds the "TMR SEGA" header that the export BIOS checks
+// before booting a cartridge.
+type SegaHeader struct {
+	Checksum     uint16 // Checksum declared in the header
+	ProductCode  int    // Decimal product code, such as 7001
+	Version      uint8
+	RegionCode   uint8 // SegaRegion* value
+	DeclaredSize int   // Bytes covered by the checksum, 0 for an unknown size code
+}
+
+// ParseSegaHeader reads the header at $7FF0. It returns false when the ROM
+// is too small or the "TMR SEGA" signature is missing.
+func ParseSegaHeader(rom []byte) (SegaHeader, bool) {
+	if len(rom) < segaHeaderAddr+16 || string(rom[segaHeaderAddr:segaHeaderAddr+8]) != "TMR SEGA" {
+		return SegaHeader{}, false
+	}
+	h := rom[segaHeaderAddr:]
+	return SegaHeader{
+		Checksum:     binary.LittleEndian.Uint16(h[0xA:]),
+		ProductCode:  fromBCD(h[0xC]) + fromBCD(h[0xD])*100 + int(h[0xE]>>4)*10000,
+		Version:      h[0xE] & 0x0F,
+		RegionCode:   h[0xF] >> 4,
+		DeclaredSize: segaROMSizes[h[0xF]&0x0F],
+	}, true
+}
+
+// ComputeChecksum returns the checksum the BIOS would calculate for the
+// header's declared size: a 16-bit sum of the ROM bytes, skipping the
+// header itself. ok is false when the size code is unknown or the ROM is
+// smaller than declared.
+func (h SegaHeader) ComputeChecksum(rom []byte) (sum uint16, ok bool) {
+	if h.DeclaredSize == 0 || h.DeclaredSize > len(rom) {
+		return 0, false
+	}
+	for i, b := range rom[:h.DeclaredSize] {
+		if i >= segaHeaderAddr && i < segaHeaderAddr+16 {
+			continue
+		}
+		sum += uint16(b)
+	}
+	return sum, true
+}
+
+// CheckSegaHeader reports problems with a ROM's Sega header that usually
+// mean a bad dump or a hacked ROM, for the frontend to show. It returns
+// nil for ROMs without a header and for Japanese ROMs, whose BIOS skips
+// the checksum and many of which ship with invalid values.
+func CheckSegaHeader(rom []byte) error {
+	hdr, ok := ParseSegaHeader(rom)
+	if !ok || hdr.RegionCode != SegaRegionSMSExport {
+		return nil
+	}
+	sum, ok := hdr.ComputeChecksum(rom)
+	switch {
+	case hdr.DeclaredSize > len(rom):
+		return fmt.Errorf("ROM is %d bytes but its header declares %d", len(rom), hdr.DeclaredSize)
+	case ok && sum != hdr.Checksum:
+		return fmt.Errorf("ROM checksum $%04X does not match header $%04X (bad dump or modified ROM)", sum, hdr.Checksum)
+	}
+	return nil
+}
+
+// SDSC header location and limits
+const (
+	sdscHeaderAddr   = 0x7FE0
+	sdscMaxStringLen = 256
+)
+
+// SDSCHeader holds the homebrew header defined by the SMS Power SDSC
+// tag. It gives a name and author for ROMs that are not in any database.
+type SDSCHeader struct {
+	Version     string // Program version, such as "1.02"
+	Date        string // Release date as YYYY-MM-DD, or "" when not set
+	Author      string
+	Name        string
+	Description string
+}
+
+// ParseSDSCHeader reads the SDSC header at $7FE0. It returns false when
+// the ROM has no header.
+func ParseSDSCHeader(rom []byte) (SDSCHeader, bool) {
+	if len(rom) < sdscHeaderAddr+16 || string(rom[sdscHeaderAddr:sdscHeaderAddr+4]) != "SDSC" {
+		return SDSCHeader{}, false
+	}
+	h := rom[sdscHeaderAddr:]
+
+	var hdr SDSCHeader
+	if isBCD(h[4]) && isBCD(h[5]) {
+		hdr.Version = fmt.Sprintf("%x.%02x", h[4], h[5])
+	}
+
+	// Day, month, then the year as little-endian BCD; all zero when unset
+	day, month, yearLo, yearHi := h[6], h[7], h[8], h[9]
+	if day != 0 && month != 0 && isBCD(day) && isBCD(month) && isBCD(yearLo) && isBCD(yearHi) {
+		hdr.Date = fmt.Sprintf("%02x%02x-%02x-%02x", yearHi, yearLo, month, day)
+	}
+
+	hdr.Author = sdscString(rom, binary.LittleEndian.Uint16(h[10:]))
+	hdr.Name = sdscString(rom, binary.LittleEndian.Uint16(h[12:]))
+	hdr.Description = sdscString(rom, binary.LittleEndian.Uint16(h[14:]))
+	return hdr, true
+}
+
+// sdscString reads a zero-terminated string pointed to by the header.
+// $FFFF marks an unused field; $0000 is treated the same, as it always
+// points at code.
+func sdscString(rom []byte, ptr uint16) string {
+	if ptr == 0xFFFF || ptr == 0 || int(ptr) >= len(rom) {
+		return ""
+	}
+	var b strings.Builder
+	for _, c := range rom[ptr:] {
+		if c == 0 || b.Len() >= sdscMaxStringLen {
+			break
+		}
+		if c >= 0x20 && c < 0x7F {
+			b.WriteByte(c)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// fromBCD decodes a two-digit BCD byte
+func fromBCD(b uint8) int {
+	return int(b>>4)*10 + int(b&0x0F)
+}
+
+// isBCD reports whether both nibbles of b are decimal digits
+func isBCD(b uint8) bool {
+	return b>>4 <= 9 && b&0x0F <= 9
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// sdscTestROM returns a 32KB ROM with an SDSC header and strings
+func sdscTestROM() []byte {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x7FE0:], []byte{
+		'S', 'D', 'S', 'C',
+		0x01, 0x02, // Version 1.02
+		0x31, 0x12, // 31 December
+		0x01, 0x20, // 2001
+		0x00, 0x70, // Author at $7000
+		0x10, 0x70, // Name at $7010
+		0xFF, 0xFF, // No description
+	})
+	copy(rom[0x7000:], "Maxim\x00")
+	copy(rom[0x7010:], "  Test\x01 Game \x00junk")
+	return rom
+}
+
+// TestParseSDSCHeader tests decoding of all header fields
+func TestParseSDSCHeader(t *testing.T) {
+	hdr, ok := ParseSDSCHeader(sdscTestROM())
+	if !ok {
+		t.Fatal("SDSC header not found")
+	}
+	want := SDSCHeader{
+		Version: "1.02",
+		Date:    "2001-12-31",
+		Author:  "Maxim",
+		Name:    "Test Game",
+	}
+	if hdr != want {
+		t.Errorf("ParseSDSCHeader: expected %+v, got %+v", want, hdr)
+	}
+}
+
+// TestParseSDSCHeader_Missing tests ROMs without a header
+func TestParseSDSCHeader_Missing(t *testing.T) {
+	if _, ok := ParseSDSCHeader(make([]byte, 0x8000)); ok {
+		t.Error("Blank ROM should have no SDSC header")
+	}
+	if _, ok := ParseSDSCHeader(sdscTestROM()[:0x4000]); ok {
+		t.Error("16KB ROM should have no SDSC header")
+	}
+}
+
+// TestParseSDSCHeader_UnsetFields tests a header with no date and
+// pointers past the end of the ROM
+func TestParseSDSCHeader_UnsetFields(t *testing.T) {
+	rom := sdscTestROM()
+	copy(rom[0x7FE6:], []byte{0, 0, 0, 0, 0x00, 0x90, 0x00, 0x00})
+
+	hdr, ok := ParseSDSCHeader(rom)
+	if !ok {
+		t.Fatal("SDSC header not found")
+	}
+	if hdr.Date != "" || hdr.Author != "" || hdr.Name != "" {
+		t.Errorf("Unset fields should be empty, got %+v", hdr)
+	}
+}
+
+// segaTestROM returns a 64KB export ROM with a valid header and checksum
+func segaTestROM() []byte {
+	rom := make([]byte, 0x10000)
+	for i := range rom {
+		rom[i] = uint8(i * 7)
+	}
+	copy(rom[0x7FF0:], "TMR SEGA\x00\x00")
+	rom[0x7FFC] = 0x01
+	rom[0x7FFD] = 0x70
+	rom[0x7FFE] = 0x12 // Product code digit 1, version 2
+	rom[0x7FFF] = 0x4E // SMS Export, 64KB
+
+	hdr, _ := ParseSegaHeader(rom)
+	sum, _ := hdr.ComputeChecksum(rom)
+	binary.LittleEndian.PutUint16(rom[0x7FFA:], sum)
+	return rom
+}
+
+// TestParseSegaHeader tests decoding of the TMR SEGA header fields
+func TestParseSegaHeader(t *testing.T) {
+	rom := segaTestROM()
+	hdr, ok := ParseSegaHeader(rom)
+	if !ok {
+		t.Fatal("Sega header not found")
+	}
+	if hdr.ProductCode != 17001 {
+		t.Errorf("ProductCode: expected 17001, got %d", hdr.ProductCode)
+	}
+	if hdr.Version != 2 {
+		t.Errorf("Version: expected 2, got %d", hdr.Version)
+	}
+	if hdr.RegionCode != SegaRegionSMSExport {
+		t.Errorf("RegionCode: expected %d, got %d", SegaRegionSMSExport, hdr.RegionCode)
+	}
+	if hdr.DeclaredSize != 0x10000 {
+		t.Errorf("DeclaredSize: expected 0x10000, got 0x%X", hdr.DeclaredSize)
+	}
+	if sum, ok := hdr.ComputeChecksum(rom); !ok || sum != hdr.Checksum {
+		t.Errorf("ComputeChecksum: expected $%04X, got $%04X (ok=%v)", hdr.Checksum, sum, ok)
+	}
+
+	// Changing the header must not affect the checksum
+	rom[0x7FFE] = 0x15
+	if sum, _ := hdr.ComputeChecksum(rom); sum != hdr.Checksum {
+		t.Error("Checksum should skip the header")
+	}
+
+	if _, ok := hdr.ComputeChecksum(rom[:0x8000]); ok {
+		t.Error("ComputeChecksum should fail for a ROM smaller than declared")
+	}
+}
+
+// TestCheckSegaHeader tests the header problems reported for a ROM
+func TestCheckSegaHeader(t *testing.T) {
+	rom := segaTestROM()
+	if err := CheckSegaHeader(rom); err != nil {
+		t.Errorf("Valid ROM: expected no error, got %v", err)
+	}
+
+	rom[0x100] ^= 0xFF
+	if err := CheckSegaHeader(rom); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Corrupt ROM: expected checksum error, got %v", err)
+	}
+
+	if err := CheckSegaHeader(rom[:0x8000]); err == nil || !strings.Contains(err.Error(), "declares") {
+		t.Errorf("Truncated ROM: expected size error, got %v", err)
+	}
+
+	// Japanese ROMs are not checked
+	rom[0x7FFF] = 0x3E
+	if err := CheckSegaHeader(rom); err != nil {
+		t.Errorf("Japanese ROM: expected no error, got %v", err)
+	}
+}
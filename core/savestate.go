@@ -0,0 +1,180 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/user-none/go-chip-sn76489"
+	"github.com/user-none/go-chip-z80"
+)
+
+// Save states from version 2 on are a header followed by chunks of
+// tag(4) + length(4) + payload. Readers skip chunks with unknown tags and
+// ignore payload bytes past the fields they know, so a new subsystem can
+// add a chunk, and an existing section can append fields, without a
+// version bump and without breaking older builds. A section may never
+// shrink or reorder its fields. Loaders read appended fields only when
+// the payload holds them, so states from before the fields were added
+// still load.
+//
+// Version 1 states are the section payloads concatenated with no chunk
+// headers, each at its section's minSize.
+const stateChunkHeaderSize = 8
+
+// stateSection describes one chunk of the save state.
+type stateSection struct {
+	tag     string // 4 ASCII bytes
+	size    int    // Payload size written by this version
+	minSize int    // Payload size before any fields were appended
+	save    func(e *Emulator, data []byte, offset int) int
+	load    func(e *Emulator, data []byte, offset int) int
+}
+
+// stateSectionCount is the number of sections in a state
+const stateSectionCount = 5
+
+// stateChunks holds the payload of each section, indexed like
+// stateSections. A fixed-size array lets loads run without allocating.
+type stateChunks [stateSectionCount][]byte
+
+// stateSections lists the chunks in the order they are written and loaded.
+var stateSections = [stateSectionCount]stateSection{
+	{"CPU ", z80.SerializeSize, z80.SerializeSize, (*Emulator).serializeCPU, (*Emulator).deserializeCPU},
+	{"MEM ", memoryStateSize, memoryBaseStateSize, (*Emulator).serializeMemory, (*Emulator).deserializeMemory},
+	{"VDP ", vdpStateSize, vdpBaseStateSize, (*Emulator).serializeVDP, (*Emulator).deserializeVDP},
+	{"PSG ", sn76489.SerializeSize, sn76489.SerializeSize, (*Emulator).serializePSG, (*Emulator).deserializePSG},
+	{"INPT", inputStateSize, inputBaseStateSize, (*Emulator).serializeInput, (*Emulator).deserializeInput},
+}
+
+// Section payload sizes
+const (
+	memoryBaseStateSize = 0x2000 + // RAM (8KB)
+		0x8000 + // Cart RAM (32KB)
+		3 + // bankSlot
+		1 // ramControl
+
+	memoryStateSize = memoryBaseStateSize +
+		2 // cartRAMUsed
+
+	vdpBaseStateSize = 0x4000 + // VRAM (16KB)
+		0x20 + // CRAM (32 bytes)
+		0x20 + // CRAM latch (32 bytes)
+		16 + // VDP registers
+		2 + // addr
+		4 + // addrLatch, writeLatch, codeReg, readBuffer
+		1 + // status
+		2 + // vCounter
+		1 + // hCounter
+		2 + // lineCounter
+		1 + // lineIntPending
+		4 + // hScrollLatch, reg2Latch, reg7Latch, vScrollLatch
+		1 // interruptCheckRequired
+
+	vdpStateSize = vdpBaseStateSize +
+		2 // hLatch, hLatched
+
+	inputBaseStateSize = 3 // Input ports (2) + ioControl (1)
+
+	inputStateSize = inputBaseStateSize +
+		1 // pauseHeld
+)
+
+// stateV1Size returns the size of a version 1 state
+func stateV1Size() int {
+	size := stateHeaderSize
+	for _, sec := range stateSections {
+		size += sec.minSize
+	}
+	return size
+}
+
+// readStateChunks walks the chunks of a version 2+ state and stores the
+// payload of each known section in chunks. It fails if a chunk runs past
+// the end of the data or a known section is missing or shorter than its
+// minSize.
+func readStateChunks(data []byte, chunks *stateChunks) error {
+	*chunks = stateChunks{}
+	offset := stateHeaderSize
+	for offset < len(data) {
+		if len(data)-offset < stateChunkHeaderSize {
+			return fmt.Errorf("save state chunk header truncated at offset %d", offset)
+		}
+		tag := data[offset : offset+4]
+		length := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		offset += stateChunkHeaderSize
+		if length > len(data)-offset {
+			return fmt.Errorf("save state chunk %q truncated", tag)
+		}
+		for i, sec := range stateSections {
+			if string(tag) == sec.tag {
+				chunks[i] = data[offset : offset+length]
+			}
+		}
+		offset += length
+	}
+
+	for i, sec := range stateSections {
+		if chunks[i] == nil {
+			return fmt.Errorf("save state missing %q section", sec.tag)
+		}
+		if len(chunks[i]) < sec.minSize {
+			return fmt.Errorf("save state %q section too short", sec.tag)
+		}
+	}
+	return nil
+}
+
+// deserializeV1 loads a flat version 1 state. Each section is handed to
+// its loader as a separate slice so appended fields are seen as absent.
+func (e *Emulator) deserializeV1(data []byte) {
+	offset := stateHeaderSize
+	for _, sec := range stateSections {
+		sec.load(e, data[offset:offset+sec.minSize], 0)
+		offset += sec.minSize
+	}
+}
+
+// FastStateSize returns the size of a fast state.
+func FastStateSize() int {
+	size := 0
+	for _, sec := range stateSections {
+		size += sec.size
+	}
+	return size
+}
+
+// FastStateSize returns the size of a fast state. It is the same for
+// every emulator; the method lets callers holding only an interface ask.
+func (e *Emulator) FastStateSize() int {
+	return FastStateSize()
+}
+
+// SerializeFast writes the section payloads into data with no header,
+// chunk tags or checksum. It is intended for in-memory snapshots taken
+// many times a second, such as rollback netplay and run-ahead, which are
+// only ever loaded by the same build and ROM. data must be at least
+// FastStateSize bytes; the size never changes while the emulator runs.
+func (e *Emulator) SerializeFast(data []byte) error {
+	if len(data) < FastStateSize() {
+		return errors.New("fast state buffer too small")
+	}
+	offset := 0
+	for _, sec := range stateSections {
+		offset = sec.save(e, data, offset)
+	}
+	return nil
+}
+
+// DeserializeFast restores a state written by SerializeFast. No
+// validation is done beyond the length check.
+func (e *Emulator) DeserializeFast(data []byte) error {
+	if len(data) < FastStateSize() {
+		return errors.New("fast state too short")
+	}
+	offset := 0
+	for _, sec := range stateSections {
+		offset = sec.load(e, data, offset)
+	}
+	return nil
+}
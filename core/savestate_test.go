@@ -0,0 +1,242 @@
+package core
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"strings"
+	"testing"
+)
+
+// finishState writes the version and data CRC into a hand-built state
+func finishState(data []byte, version uint16) []byte {
+	binary.LittleEndian.PutUint16(data[12:14], version)
+	binary.LittleEndian.PutUint32(data[18:22], crc32.ChecksumIEEE(data[stateHeaderSize:]))
+	return data
+}
+
+// toV1State converts a v2 state to the flat v1 layout by dropping the
+// chunk headers and any fields appended to sections since v1
+func toV1State(state []byte) []byte {
+	v1 := append([]byte(nil), state[:stateHeaderSize]...)
+	offset := stateHeaderSize
+	for _, sec := range stateSections {
+		length := int(binary.LittleEndian.Uint32(state[offset+4:]))
+		offset += stateChunkHeaderSize
+		v1 = append(v1, state[offset:offset+sec.minSize]...)
+		offset += length
+	}
+	return finishState(v1, 1)
+}
+
+// appendChunk appends a tagged chunk to a state
+func appendChunk(state []byte, tag string, payload []byte) []byte {
+	var hdr [stateChunkHeaderSize]byte
+	copy(hdr[:], tag)
+	binary.LittleEndian.PutUint32(hdr[4:], uint32(len(payload)))
+	state = append(state, hdr[:]...)
+	return append(state, payload...)
+}
+
+// savedTestState returns an emulator and a v2 state with recognizable
+// RAM and VDP register contents
+func savedTestState(t *testing.T) (*Emulator, []byte) {
+	t.Helper()
+	e := createTestEmulator()
+	e.mem.Set(0xC000, 0xAB)
+	e.vdp.WriteControl(0x55)
+	e.vdp.WriteControl(0x80)
+
+	state, err := e.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	e.mem.Set(0xC000, 0x00)
+	e.vdp.WriteControl(0x00)
+	e.vdp.WriteControl(0x80)
+	return e, state
+}
+
+// checkRestored verifies the values written by savedTestState
+func checkRestored(t *testing.T, e *Emulator) {
+	t.Helper()
+	if got := e.mem.Get(0xC000); got != 0xAB {
+		t.Errorf("RAM[0xC000]: expected 0xAB, got 0x%02X", got)
+	}
+	if got := e.vdp.GetRegister(0); got != 0x55 {
+		t.Errorf("VDP Register 0: expected 0x55, got 0x%02X", got)
+	}
+}
+
+// TestSaveState_LoadsV1 tests that flat version 1 states still load
+func TestSaveState_LoadsV1(t *testing.T) {
+	e, state := savedTestState(t)
+
+	v1 := toV1State(state)
+	if len(v1) != stateV1Size() {
+		t.Fatalf("v1 size: expected %d, got %d", stateV1Size(), len(v1))
+	}
+	if err := e.Deserialize(v1); err != nil {
+		t.Fatalf("Deserialize v1 failed: %v", err)
+	}
+	checkRestored(t, e)
+}
+
+// TestSaveState_V1TooShort tests the v1 length check
+func TestSaveState_V1TooShort(t *testing.T) {
+	e, state := savedTestState(t)
+
+	v1 := toV1State(state)
+	v1 = finishState(v1[:len(v1)-1], 1)
+	if err := e.VerifyState(v1); err == nil {
+		t.Error("Truncated v1 state should fail verification")
+	}
+}
+
+// TestSaveState_SkipsUnknownChunk tests that a chunk added by a newer
+// build is ignored
+func TestSaveState_SkipsUnknownChunk(t *testing.T) {
+	e, state := savedTestState(t)
+
+	state = appendChunk(state, "FM  ", []byte{1, 2, 3, 4, 5})
+	if err := e.Deserialize(finishState(state, stateVersion)); err != nil {
+		t.Fatalf("Deserialize with unknown chunk failed: %v", err)
+	}
+	checkRestored(t, e)
+}
+
+// TestSaveState_ExtendedSection tests that fields appended to a known
+// section by a newer build are ignored
+func TestSaveState_ExtendedSection(t *testing.T) {
+	e, state := savedTestState(t)
+
+	// Rebuild with two extra bytes on the INPT section (the last chunk)
+	inputOff := len(state) - inputStateSize
+	payload := append(append([]byte(nil), state[inputOff:]...), 0xEE, 0xEE)
+	state = appendChunk(state[:inputOff-stateChunkHeaderSize], "INPT", payload)
+
+	if err := e.Deserialize(finishState(state, stateVersion)); err != nil {
+		t.Fatalf("Deserialize with extended section failed: %v", err)
+	}
+	checkRestored(t, e)
+}
+
+// TestSaveState_ChunkErrors tests rejection of malformed chunk layouts
+func TestSaveState_ChunkErrors(t *testing.T) {
+	_, state := savedTestState(t)
+	inputOff := len(state) - inputStateSize
+
+	testCases := []struct {
+		name  string
+		build func() []byte
+		want  string
+	}{
+		{"missing section", func() []byte {
+			return append([]byte(nil), state[:inputOff-stateChunkHeaderSize]...)
+		}, "missing"},
+		{"short section", func() []byte {
+			s := append([]byte(nil), state[:inputOff-stateChunkHeaderSize]...)
+			return appendChunk(s, "INPT", []byte{0xFF})
+		}, "too short"},
+		{"truncated chunk", func() []byte {
+			return append([]byte(nil), state[:len(state)-1]...)
+		}, "truncated"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := createTestEmulator()
+			err := e.VerifyState(finishState(tc.build(), stateVersion))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("Expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+// TestSaveState_FastRoundTrip tests the headerless fast state path
+func TestSaveState_FastRoundTrip(t *testing.T) {
+	e, _ := savedTestState(t)
+	e.mem.Set(0xC000, 0xAB)
+	e.vdp.WriteControl(0x55)
+	e.vdp.WriteControl(0x80)
+
+	buf := make([]byte, FastStateSize())
+	if err := e.SerializeFast(buf); err != nil {
+		t.Fatalf("SerializeFast failed: %v", err)
+	}
+	want := SerializeSize() - stateHeaderSize - len(stateSections)*stateChunkHeaderSize
+	if FastStateSize() != want {
+		t.Errorf("FastStateSize: expected %d, got %d", want, FastStateSize())
+	}
+	if e.FastStateSize() != FastStateSize() {
+		t.Errorf("Emulator.FastStateSize: expected %d, got %d", FastStateSize(), e.FastStateSize())
+	}
+
+	e.mem.Set(0xC000, 0x00)
+	e.vdp.WriteControl(0x00)
+	e.vdp.WriteControl(0x80)
+	if err := e.DeserializeFast(buf); err != nil {
+		t.Fatalf("DeserializeFast failed: %v", err)
+	}
+	checkRestored(t, e)
+
+	if err := e.SerializeFast(buf[:len(buf)-1]); err == nil {
+		t.Error("SerializeFast into a short buffer should fail")
+	}
+	if err := e.DeserializeFast(buf[:len(buf)-1]); err == nil {
+		t.Error("DeserializeFast of a short state should fail")
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		e.SerializeFast(buf)
+	})
+	if allocs != 0 {
+		t.Errorf("SerializeFast allocated %.0f times per run", allocs)
+	}
+}
+
+// TestSaveState_HCounterLatch tests that the H counter latch is saved
+// and that states from before it was added load with it released
+func TestSaveState_HCounterLatch(t *testing.T) {
+	e, _ := savedTestState(t)
+	e.vdp.LatchHCounter(50)
+	want := e.vdp.ReadHCounter()
+
+	state, err := e.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	e.vdp.ReleaseHCounter()
+	e.vdp.SetHCounter(want + 1)
+	if err := e.Deserialize(state); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if got := e.vdp.ReadHCounter(); got != want {
+		t.Errorf("H counter after load: expected latched 0x%02X, got 0x%02X", want, got)
+	}
+
+	// Rebuild the state with the VDP section cut back to its original size
+	var chunks stateChunks
+	if err := readStateChunks(state, &chunks); err != nil {
+		t.Fatalf("readStateChunks failed: %v", err)
+	}
+	old := append([]byte(nil), state[:stateHeaderSize]...)
+	for i, sec := range stateSections {
+		old = appendChunk(old, sec.tag, chunks[i][:sec.minSize])
+	}
+	if err := e.Deserialize(finishState(old, stateVersion)); err != nil {
+		t.Fatalf("Deserialize of state without latch failed: %v", err)
+	}
+	if e.vdp.hLatched {
+		t.Error("H counter should not be latched after loading an older state")
+	}
+
+	// Older states diff against current ones without the latch fields
+	diffs, err := DiffStates(old, state)
+	if err != nil {
+		t.Fatalf("DiffStates failed: %v", err)
+	}
+	if len(diffs) != 2 || diffs[0].Field != "H latch" || diffs[1].Field != "H latched" {
+		t.Errorf("Expected only the latch fields to differ, got %v", diffs)
+	}
+}
@@ -0,0 +1,128 @@
+package core
+
+import "fmt"
+
+// Battery RAM sizes. Cartridges fitted 8KB, 16KB or 32KB; saves are
+// rounded up to one of these.
+const (
+	minSRAMSize = 0x2000
+	maxSRAMSize = 0x8000
+)
+
+// sramFlushDelaySeconds is how long cartridge RAM must go unwritten
+// before SRAMFlushDue reports it. Games write saves in bursts over a few
+// frames; waiting for quiet avoids writing a half-updated save.
+const sramFlushDelaySeconds = 2
+
+// romSRAMSizes maps ROM CRC32 to a known battery RAM size. Entries come
+// from external ROM database files (see LoadROMDatabase).
+var romSRAMSizes = map[uint32]int{}
+
+// validSRAMSize reports whether size is a battery RAM size a cartridge
+// can have
+func validSRAMSize(size int) bool {
+	return size == 0x2000 || size == 0x4000 || size == 0x8000
+}
+
+// roundSRAMSize rounds a used byte count up to a cartridge RAM size
+func roundSRAMSize(used int) int {
+	size := minSRAMSize
+	for size < used && size < maxSRAMSize {
+		size <<= 1
+	}
+	return size
+}
+
+// contentSRAMUsed returns the cartridge RAM in use judged by its
+// contents: the offset of the last non-zero byte, plus one
+func (m *Memory) contentSRAMUsed() int {
+	for i := len(m.cartRAM) - 1; i >= 0; i-- {
+		if m.cartRAM[i] != 0 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// SRAMSize returns the size of the battery save: the RAM the game has
+// used, rounded up to a cartridge RAM size and never smaller than the ROM
+// database size. The used mark depends only on the loaded save and the
+// writes made since, and is kept in save states, so the size is the same
+// in every run that reaches the same state.
+func (m *Memory) SRAMSize() int {
+	size := roundSRAMSize(m.cartRAMUsed)
+	if m.knownSRAMSize > size {
+		size = m.knownSRAMSize
+	}
+	return size
+}
+
+// mappedSRAMSize returns the save RAM region size reported through
+// MemoryMap. Frontends allocate that region once at load, before the game
+// has used any RAM, so it must cover everything the game could write: the
+// ROM database size when known, otherwise the full 32KB.
+func (m *Memory) mappedSRAMSize() int {
+	if m.knownSRAMSize > 0 {
+		return m.knownSRAMSize
+	}
+	return maxSRAMSize
+}
+
+// ImportSRAM converts a battery save written by another SMS emulator
+// (.srm or .sav) into the layout GetSRAM produces. Those files are raw
+// cartridge RAM, sometimes padded with zeros past 32KB; the padding is
+// dropped and the result is sized like a save this emulator would write.
+// Files too large to be cartridge RAM, such as save states, are rejected.
+func ImportSRAM(data []byte) ([]byte, error) {
+	used := 0
+	for i := len(data) - 1; i >= 0; i-- {
+		if data[i] != 0 {
+			used = i + 1
+			break
+		}
+	}
+	if used > maxSRAMSize {
+		return nil, fmt.Errorf("save file is %d bytes: larger than 32KB cartridge RAM", len(data))
+	}
+	size := roundSRAMSize(used)
+	if len(data) <= maxSRAMSize && validSRAMSize(len(data)) && len(data) > size {
+		size = len(data)
+	}
+	out := make([]byte, size)
+	copy(out, data[:used])
+	return out, nil
+}
+
+// updateSRAMDirty tracks unsaved cartridge RAM writes at the end of each
+// frame
+func (e *Emulator) updateSRAMDirty() {
+	switch {
+	case e.mem.cartRAMWritten:
+		e.sramDirty = true
+		e.sramQuietFrames = 0
+	case e.sramDirty:
+		e.sramQuietFrames++
+	}
+}
+
+// SRAMDirty reports whether cartridge RAM has been written since the last
+// MarkSRAMSaved.
+func (e *Emulator) SRAMDirty() bool {
+	return e.sramDirty
+}
+
+// SRAMFlushDue reports whether unsaved cartridge RAM has gone unwritten
+// for a couple of seconds of emulated time. Frontends check it after each
+// RunFrame, write GetSRAM to disk and call MarkSRAMSaved, so battery
+// saves reach disk soon after the game makes them without writing on
+// every frame of a save.
+func (e *Emulator) SRAMFlushDue() bool {
+	return e.sramDirty && e.sramQuietFrames >= sramFlushDelaySeconds*e.timing.FPS
+}
+
+// MarkSRAMSaved clears the dirty state after the frontend has written the
+// save.
+func (e *Emulator) MarkSRAMSaved() {
+	e.sramDirty = false
+	e.sramQuietFrames = 0
+}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	"github.com/user-none/eblitui/coreif"
+)
+
+// TestSRAM_SizeTracksUsage tests that saves grow with the RAM the game
+// uses while the region reported to frontends stays 32KB
+func TestSRAM_SizeTracksUsage(t *testing.T) {
+	e := createTestEmulator()
+	check := func(when string, want int) {
+		t.Helper()
+		if got := len(e.GetSRAM()); got != want {
+			t.Errorf("%s: GetSRAM expected %d bytes, got %d", when, want, got)
+		}
+		if got := e.MemoryMap()[1].Size; got != 0x8000 {
+			t.Errorf("%s: MemoryMap save RAM expected 32KB, got %d", when, got)
+		}
+		if got := len(e.ReadRegion(coreif.MemorySaveRAM)); got != 0x8000 {
+			t.Errorf("%s: ReadRegion save RAM expected 32KB, got %d", when, got)
+		}
+	}
+	check("Unused", 0x2000)
+
+	e.mem.Set(0xFFFC, 0x08) // Cart RAM bank 0 at $8000
+	e.mem.Set(0x9FFF, 0x01)
+	check("Write at offset $1FFF", 0x2000)
+
+	e.mem.Set(0xA000, 0x01)
+	check("Write at offset $2000", 0x4000)
+
+	e.mem.Set(0xFFFC, 0x0C) // Cart RAM bank 1
+	e.mem.Set(0x8000, 0x00)
+	check("Write to bank 1", 0x8000)
+}
+
+// TestSRAM_LoadedSaveSize tests that a loaded save sets the size from its
+// contents, independent of earlier writes
+func TestSRAM_LoadedSaveSize(t *testing.T) {
+	e := createTestEmulator()
+	e.mem.Set(0xFFFC, 0x0C)
+	e.mem.Set(0x8000, 0x01) // Raise the mark to 32KB
+
+	// Old 32KB save with data only in the first 8KB
+	save := make([]byte, 0x8000)
+	save[0x100] = 0xAA
+	e.SetSRAM(save)
+	sram := e.GetSRAM()
+	if len(sram) != 0x2000 || sram[0x100] != 0xAA {
+		t.Errorf("32KB save using 8KB: expected 8KB with data kept, got %d bytes", len(sram))
+	}
+
+	save[0x5000] = 0xBB
+	e.SetSRAM(save)
+	sram = e.GetSRAM()
+	if len(sram) != 0x8000 || sram[0x5000] != 0xBB {
+		t.Errorf("Save using 21KB: expected 32KB with data kept, got %d bytes", len(sram))
+	}
+}
+
+// TestSRAM_SizeInSaveState tests that loading a state restores the size
+// it was saved with, whatever the session did before
+func TestSRAM_SizeInSaveState(t *testing.T) {
+	e := createTestEmulator()
+	e.mem.Set(0xFFFC, 0x08)
+	e.mem.Set(0xA000, 0x00) // Zero written at offset $2000: 16KB
+
+	state, err := e.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	fast := make([]byte, FastStateSize())
+	if err := e.SerializeFast(fast); err != nil {
+		t.Fatalf("SerializeFast failed: %v", err)
+	}
+
+	e.mem.Set(0xFFFC, 0x0C)
+	e.mem.Set(0x8000, 0x01)
+	if err := e.Deserialize(state); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if got := len(e.GetSRAM()); got != 0x4000 {
+		t.Errorf("After state load: expected 16KB, got %d", got)
+	}
+
+	e.mem.Set(0xFFFC, 0x0C)
+	e.mem.Set(0x8000, 0x01)
+	if err := e.DeserializeFast(fast); err != nil {
+		t.Fatalf("DeserializeFast failed: %v", err)
+	}
+	if got := len(e.GetSRAM()); got != 0x4000 {
+		t.Errorf("After fast state load: expected 16KB, got %d", got)
+	}
+}
+
+// TestSRAM_DatabaseSize tests sizes from an external ROM database and
+// the save RAM region reported to libretro
+func TestSRAM_DatabaseSize(t *testing.T) {
+	restoreROMDatabase(t)
+	t.Cleanup(func() { romSRAMSizes = map[uint32]int{} })
+
+	rom := createTestROM(4)
+	db := fmt.Sprintf(`[{"crc32": "%08x", "sram": 16384}]`, crc32.ChecksumIEEE(rom))
+	if _, err := LoadROMDatabase(strings.NewReader(db)); err != nil {
+		t.Fatalf("LoadROMDatabase failed: %v", err)
+	}
+
+	e, _ := NewEmulator(rom)
+	if got := len(e.GetSRAM()); got != 0x4000 {
+		t.Errorf("GetSRAM: expected 16KB, got %d", got)
+	}
+	e.mem.Set(0xFFFC, 0x0C)
+	e.mem.Set(0x8000, 0x01)
+	if got := len(e.GetSRAM()); got != 0x8000 {
+		t.Errorf("GetSRAM after writing past the database size: expected 32KB, got %d", got)
+	}
+	if got := e.MemoryMap()[1].Size; got != 0x4000 {
+		t.Errorf("MemoryMap save RAM: expected 16KB, got %d", got)
+	}
+	if got := len(e.ReadRegion(coreif.MemorySaveRAM)); got != 0x4000 {
+		t.Errorf("ReadRegion save RAM: expected 16KB, got %d", got)
+	}
+
+	if _, err := LoadROMDatabase(strings.NewReader(`[{"crc32": "12345678", "sram": 1000}]`)); err == nil {
+		t.Error("Invalid sram size should be rejected")
+	}
+}
+
+// TestSRAM_FlushDebounce tests that a flush is due only after writes stop
+func TestSRAM_FlushDebounce(t *testing.T) {
+	rom := createTestROM(4)
+	copy(rom, []byte{
+		0xF3,       // DI
+		0x3E, 0x08, // LD A,$08
+		0x32, 0xFC, 0xFF, // LD ($FFFC),A ; map cart RAM at $8000
+		0x32, 0x00, 0x80, // LD ($8000),A
+		0x18, 0xFE, // JR $
+	})
+	e, _ := NewEmulator(rom)
+
+	e.RunFrame()
+	if !e.SRAMDirty() {
+		t.Fatal("SRAM should be dirty after a write")
+	}
+	if e.SRAMFlushDue() {
+		t.Error("Flush should not be due right after a write")
+	}
+
+	delay := sramFlushDelaySeconds * e.GetTiming().FPS
+	for i := 0; i < delay-1; i++ {
+		e.RunFrame()
+	}
+	if e.SRAMFlushDue() {
+		t.Error("Flush should not be due before the delay")
+	}
+	e.RunFrame()
+	if !e.SRAMFlushDue() {
+		t.Error("Flush should be due after the delay")
+	}
+
+	e.MarkSRAMSaved()
+	e.RunFrame()
+	if e.SRAMDirty() || e.SRAMFlushDue() {
+		t.Error("SRAM should be clean after MarkSRAMSaved")
+	}
+}
+
+// TestImportSRAM tests conversion of battery saves from other emulators
+func TestImportSRAM(t *testing.T) {
+	// Raw 8KB save is kept as-is
+	raw := make([]byte, 0x2000)
+	raw[0x10] = 0xAB
+	out, err := ImportSRAM(raw)
+	if err != nil {
+		t.Fatalf("ImportSRAM failed: %v", err)
+	}
+	if len(out) != 0x2000 || out[0x10] != 0xAB {
+		t.Errorf("8KB save: got %d bytes, byte 0x10 = 0x%02X", len(out), out[0x10])
+	}
+
+	// Zero padding past 32KB is dropped
+	padded := make([]byte, 0x10000)
+	padded[0x3000] = 0x55
+	out, err = ImportSRAM(padded)
+	if err != nil {
+		t.Fatalf("ImportSRAM failed: %v", err)
+	}
+	if len(out) != 0x4000 || out[0x3000] != 0x55 {
+		t.Errorf("Padded save: got %d bytes, want 0x4000", len(out))
+	}
+
+	// Odd-sized files round up to a cartridge size
+	out, err = ImportSRAM([]byte{1, 2, 3})
+	if err != nil || len(out) != 0x2000 {
+		t.Errorf("Short save: got %d bytes, err %v", len(out), err)
+	}
+
+	// Data beyond 32KB is not cartridge RAM
+	big := make([]byte, 0x9000)
+	big[0x8800] = 1
+	if _, err := ImportSRAM(big); err == nil {
+		t.Error("Expected error for data beyond 32KB")
+	}
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// StateDiff is one save state field whose bytes differ between two states.
+type StateDiff struct {
+	Section string // Chunk tag, such as "CPU" or "VDP"
+	Field   string // Field within the section, such as "PC" or "VRAM"
+	A, B    []byte // The field's bytes in each state
+}
+
+// Differing returns the offsets within the field of the bytes that differ.
+func (d StateDiff) Differing() []int {
+	var offsets []int
+	for i := range d.A {
+		if d.A[i] != d.B[i] {
+			offsets = append(offsets, i)
+		}
+	}
+	return offsets
+}
+
+// stateField names a run of bytes in a section payload
+type stateField struct {
+	name string
+	size int
+}
+
+// stateFieldLayouts describes each section's payload, indexed like
+// stateSections. The CPU and PSG layouts follow the go-chip serializers.
+var stateFieldLayouts = [stateSectionCount][]stateField{
+	{
+		{"version", 1}, {"AF", 2}, {"BC", 2}, {"DE", 2}, {"HL", 2},
+		{"AF'", 2}, {"BC'", 2}, {"DE'", 2}, {"HL'", 2},
+		{"IX", 2}, {"IY", 2}, {"SP", 2}, {"PC", 2}, {"I", 1}, {"R", 1},
+		{"IFF1", 1}, {"IFF2", 1}, {"IM", 1}, {"halted", 1},
+		{"cycles", 8}, {"deficit", 4}, {"INT line", 1}, {"INT data", 1},
+		{"NMI pending", 1}, {"after EI", 1},
+	},
+	{
+		{"RAM", 0x2000}, {"cart RAM", 0x8000}, {"bank slots", 3}, {"RAM control", 1},
+		{"cart RAM used", 2},
+	},
+	{
+		{"VRAM", 0x4000}, {"CRAM", 0x20}, {"CRAM latch", 0x20}, {"registers", 16},
+		{"address", 2}, {"address latch", 1}, {"write latch", 1}, {"code", 1},
+		{"read buffer", 1}, {"status", 1}, {"V counter", 2}, {"H counter", 1},
+		{"line counter", 2}, {"line IRQ pending", 1},
+		{"H scroll latch", 1}, {"reg 2 latch", 1}, {"reg 7 latch", 1}, {"V scroll latch", 1},
+		{"interrupt check", 1}, {"H latch", 1}, {"H latched", 1},
+	},
+	{
+		{"version", 1}, {"tone periods", 6}, {"tone counters", 6}, {"tone outputs", 3},
+		{"noise register", 1}, {"noise counter", 2}, {"noise shift", 2}, {"noise toggle", 1},
+		{"volumes", 4}, {"latched channel", 1}, {"latched type", 1},
+		{"clock divider", 4}, {"clock counter", 8}, {"noise output", 1},
+	},
+	{
+		{"port 1", 1}, {"port 2", 1}, {"I/O control", 1}, {"pause held", 1},
+	},
+}
+
+// DiffStates compares two save states for the same ROM field by field and
+// returns the fields that differ, in state order. The data checksums are
+// not checked so damaged states can still be inspected. Bytes a newer
+// build appended to a section are compared as one "extra" field.
+func DiffStates(a, b []byte) ([]StateDiff, error) {
+	var chunksA, chunksB stateChunks
+	if err := splitState(a, &chunksA); err != nil {
+		return nil, fmt.Errorf("first state: %w", err)
+	}
+	if err := splitState(b, &chunksB); err != nil {
+		return nil, fmt.Errorf("second state: %w", err)
+	}
+	if binary.LittleEndian.Uint32(a[14:18]) != binary.LittleEndian.Uint32(b[14:18]) {
+		return nil, errors.New("save states are for different ROMs")
+	}
+
+	var diffs []StateDiff
+	for i, sec := range stateSections {
+		section := strings.TrimSpace(sec.tag)
+		// Fields appended since an older state was saved compare as zero
+		chunkA, chunkB := padSection(chunksA[i], sec.size), padSection(chunksB[i], sec.size)
+		offset := 0
+		for _, field := range stateFieldLayouts[i] {
+			fa := chunkA[offset : offset+field.size]
+			fb := chunkB[offset : offset+field.size]
+			if string(fa) != string(fb) {
+				diffs = append(diffs, StateDiff{section, field.name, fa, fb})
+			}
+			offset += field.size
+		}
+
+		extraA, extraB := chunkA[offset:], chunkB[offset:]
+		if string(extraA) != string(extraB) {
+			// Pad the shorter side so offsets line up
+			n := max(len(extraA), len(extraB))
+			fa, fb := make([]byte, n), make([]byte, n)
+			copy(fa, extraA)
+			copy(fb, extraB)
+			diffs = append(diffs, StateDiff{section, "extra", fa, fb})
+		}
+	}
+	return diffs, nil
+}
+
+// splitState checks a state header and fills chunks with the section
+// payloads of either state format
+func splitState(data []byte, chunks *stateChunks) error {
+	if len(data) < stateHeaderSize {
+		return errors.New("save state too short")
+	}
+	if string(data[0:12]) != stateMagic {
+		return errors.New("invalid save state magic")
+	}
+	version := binary.LittleEndian.Uint16(data[12:14])
+	if version > stateVersion {
+		return errors.New("unsupported save state version")
+	}
+	if version >= 2 {
+		return readStateChunks(data, chunks)
+	}
+
+	if len(data) < stateV1Size() {
+		return errors.New("save state too short")
+	}
+	offset := stateHeaderSize
+	for i, sec := range stateSections {
+		chunks[i] = data[offset : offset+sec.minSize]
+		offset += sec.minSize
+	}
+	return nil
+}
+
+// padSection extends a section payload shorter than size with zeros
+func padSection(payload []byte, size int) []byte {
+	if len(payload) >= size {
+		return payload
+	}
+	padded := make([]byte, size)
+	copy(padded, payload)
+	return padded
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// TestStateDiff_LayoutSizes tests that the field layouts cover each section
+func TestStateDiff_LayoutSizes(t *testing.T) {
+	for i, sec := range stateSections {
+		size := 0
+		for _, field := range stateFieldLayouts[i] {
+			size += field.size
+		}
+		if size != sec.size {
+			t.Errorf("%q layout covers %d bytes, section is %d", sec.tag, size, sec.size)
+		}
+	}
+}
+
+// TestDiffStates tests that changed fields are reported by name
+func TestDiffStates(t *testing.T) {
+	e, a := savedTestState(t)
+	b, err := e.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	diffs, err := DiffStates(a, b)
+	if err != nil {
+		t.Fatalf("DiffStates failed: %v", err)
+	}
+
+	found := map[string]StateDiff{}
+	for _, d := range diffs {
+		found[d.Section+" "+d.Field] = d
+	}
+	ram, ok := found["MEM RAM"]
+	if !ok {
+		t.Fatalf("RAM difference not reported: %v", diffs)
+	}
+	if got := ram.Differing(); len(got) != 1 || got[0] != 0 {
+		t.Errorf("RAM differing offsets: got %v, want [0]", got)
+	}
+	regs, ok := found["VDP registers"]
+	if !ok {
+		t.Fatalf("VDP register difference not reported: %v", diffs)
+	}
+	if regs.A[0] != 0x55 || regs.B[0] != 0x00 {
+		t.Errorf("VDP register 0: got 0x%02X -> 0x%02X", regs.A[0], regs.B[0])
+	}
+}
+
+// TestDiffStates_Formats tests that a v1 and v2 copy of a state match
+func TestDiffStates_Formats(t *testing.T) {
+	_, state := savedTestState(t)
+	diffs, err := DiffStates(state, toV1State(state))
+	if err != nil {
+		t.Fatalf("DiffStates failed: %v", err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("Expected no differences, got %v", diffs)
+	}
+}
+
+// TestDiffStates_DifferentROM tests that states for different ROMs are
+// rejected
+func TestDiffStates_DifferentROM(t *testing.T) {
+	_, a := savedTestState(t)
+	b := append([]byte(nil), a...)
+	binary.LittleEndian.PutUint32(b[14:18], 0x12345678)
+	if _, err := DiffStates(a, b); err == nil {
+		t.Error("Expected error for states from different ROMs")
+	}
+	if _, err := DiffStates(a, b[:10]); err == nil {
+		t.Error("Expected error for truncated state")
+	}
+}
@@ -7,6 +7,10 @@ import (
 
 // VDP timing constants (in CPU cycles within a scanline)
 const (
+	// Every scanline is 684 master clocks, 228 CPU cycles, on both NTSC and
+	// PAL consoles. The frame loop runs lines of 226-228 cycles so frames
+	// keep the nominal FPS.
+	CyclesPerScanline = 228
 	// Cycle at which VBlank interrupt is triggered
 	// Real hardware fires VBlank slightly after scanline start
 	VBlankInterruptCycle = 4
@@ -94,6 +98,8 @@ type VDP struct {
 	status         uint8         // Status register
 	vCounter       uint16        // Current scanline (raw)
 	hCounter       uint8         // Horizontal counter
+	hLatch         uint8         // H counter captured on a TH falling edge
+	hLatched       bool          // hLatch is returned by H counter reads
 	lineCounter    int16         // Line interrupt counter
 	lineIntPending bool          // Line interrupt pending flag
 	bgPriority     [256]bool     // Background priority flags for current scanline
@@ -113,18 +119,51 @@ type VDP struct {
 
 	// Pre-allocated for sprite collision detection (avoids per-scanline allocation)
 	spritePixels []bool
-}
 
-// Palette scale: 2-bit SMS color to 8-bit RGB
-var paletteScale = []uint8{0, 85, 170, 255}
+	// Debug: bit N set hides SAT entry N from the output (see SetSpriteMasked)
+	spriteMask uint64
+
+	// When set, sprites beyond the 8-per-line hardware limit are still drawn
+	noSpriteLimit bool
+
+	// When set, sprite overflow and collision are evaluated on blanked lines
+	blankedCollision bool
+
+	// Output color for each 6-bit SMS color value
+	palette    Palette
+	palette565 [64]uint16 // palette packed for RGB565 output
+
+	// Render target for formats other than RGBA (see pixelformat.go)
+	format            PixelFormat
+	packedFramebuffer []byte
+
+	// Decoded patterns, refreshed lazily after VRAM writes
+	tiles tileCache
+
+	// VRAM access slot modelling (see vdp_access.go)
+	accessTiming  bool
+	lastVRAMWrite int // Frame cycle of the last stored VRAM write
+
+	// Mid-scanline write tracking (see vdp_midline.go)
+	midLineWrites bool        // Split rendering at timestamped writes when set
+	lineCycle     int         // CPU cycle within the current scanline
+	lineWrites    []lineWrite // Writes made after the per-line latch point
+	lineBuffer    []byte      // Scratch row used to assemble split scanlines
+}
 
 func NewVDP() *VDP {
-	return &VDP{
+	v := &VDP{
 		framebuffer:    image.NewRGBA(image.Rect(0, 0, ScreenWidth, MaxScreenHeight)),
 		totalScanlines: 262, // Default to NTSC
 		lineCounter:    255, // Prevent spurious interrupt on first scanline
 		spritePixels:   make([]bool, ScreenWidth),
+		lineWrites:     make([]lineWrite, 0, maxLineWrites),
+		lineBuffer:     make([]byte, ScreenWidth*4),
+		palette:        OriginalPalette(),
 	}
+	v.tiles.invalidateAll()
+	v.updatePalette565()
+	return v
 }
 
 // SetTotalScanlines configures the VDP for the correct region timing
@@ -153,6 +192,13 @@ func (v *VDP) ReadVCounter() uint8 {
 				return uint8(line)
 			}
 			return uint8(line - 57) // 259->202, 312->255
+		case 240:
+			// 240-line mode: 0-266 normal (wrapping through zero),
+			// 267-312 maps to 210-255
+			if line <= 266 {
+				return uint8(line)
+			}
+			return uint8(line - 57) // 267->210, 312->255
 		}
 	} else {
 		// NTSC timing (262 scanlines)
@@ -174,11 +220,27 @@ func (v *VDP) ReadVCounter() uint8 {
 	return uint8(line)
 }
 
-// ReadHCounter returns the horizontal counter
+// ReadHCounter returns the horizontal counter. While a TH line is held
+// low after a falling edge, the value latched by the edge is returned.
 func (v *VDP) ReadHCounter() uint8 {
+	if v.hLatched {
+		return v.hLatch
+	}
 	return v.hCounter
 }
 
+// LatchHCounter captures the H counter at a CPU cycle within the current
+// scanline. Cycles past the end of the line fall in the next one.
+func (v *VDP) LatchHCounter(cycle int) {
+	v.hLatch = GetHCounterForCycle(cycle % len(hCounterTable))
+	v.hLatched = true
+}
+
+// ReleaseHCounter returns H counter reads to the running counter
+func (v *VDP) ReleaseHCounter() {
+	v.hLatched = false
+}
+
 // SetHCounter updates the horizontal counter
 func (v *VDP) SetHCounter(h uint8) {
 	v.hCounter = h
@@ -187,19 +249,32 @@ func (v *VDP) SetHCounter(h uint8) {
 // ActiveHeight returns the active display height based on mode
 // 192 lines: standard Mode 4 (default)
 // 224 lines: M2=1, M1=1
-// Where: M2 = reg0 bit 1, M1 = reg1 bit 4
+// 240 lines: M2=1, M3=1, M1=0 (PAL only)
+// Where: M2 = reg0 bit 1, M1 = reg1 bit 4, M3 = reg1 bit 3
 func (v *VDP) ActiveHeight() int {
 	m2 := v.register[0]&0x02 != 0 // Register 0 bit 1 - extended mode enable
 	m1 := v.register[1]&0x10 != 0 // Register 1 bit 4
+	m3 := v.register[1]&0x08 != 0 // Register 1 bit 3
 
-	// Only enable 224-line mode when both M2 and M1 are set
-	// 240-line mode (M2=1, M1=0) is not supported on SMS
 	if m2 && m1 {
 		return 224
 	}
+	// 240-line mode leaves no vertical blanking on NTSC (262 lines), so the
+	// signal rolls on real hardware. Only honor it on PAL and fall back to
+	// 192 lines on NTSC.
+	if m2 && m3 && v.totalScanlines == 313 {
+		return 240
+	}
 	return 192
 }
 
+// Requested240LineMode returns true when the mode bits select 240-line
+// mode (M2=1, M3=1, M1=0), regardless of whether the current video
+// standard can display it.
+func (v *VDP) Requested240LineMode() bool {
+	return v.register[0]&0x02 != 0 && v.register[1]&0x18 == 0x08
+}
+
 // ReadControl returns the status register and clears flags
 func (v *VDP) ReadControl() uint8 {
 	status := v.status
@@ -255,6 +330,9 @@ func (v *VDP) WriteControl(value uint8) {
 			regNum := value & 0x0F
 			if regNum < 16 {
 				v.register[regNum] = v.addrLatch
+				if regNum == 2 || regNum == 7 || regNum == 8 {
+					v.recordLineWrite(lineWriteRegister, regNum, v.addrLatch)
+				}
 				// Interrupt enable bits are in reg0 bit 4 (line) and reg1 bit 5 (frame)
 				// Writing to these registers may require interrupt state update
 				if regNum == 0 || regNum == 1 {
@@ -287,25 +365,24 @@ func (v *VDP) WriteData(value uint8) {
 		// CRAM write
 		cramAddr := v.addr & 0x1F
 		v.cram[cramAddr] = value
-	} else {
-		// VRAM write
+		v.recordLineWrite(lineWriteCRAM, uint8(cramAddr), value)
+	} else if v.vramWriteStored() {
+		// VRAM write. The address advances even when the write is lost.
 		v.vram[v.addr&0x3FFF] = value
+		v.tiles.invalidate(v.addr)
 	}
 	v.addr = (v.addr + 1) & 0x3FFF
 }
 
 // cramToColor converts a CRAM entry to RGBA using the latched CRAM values
 func (v *VDP) cramToColor(index uint8) color.RGBA {
-	c := v.cramLatch[index&0x1F]
-	r := (c >> 0) & 0x03
-	g := (c >> 2) & 0x03
-	b := (c >> 4) & 0x03
-	return color.RGBA{
-		R: paletteScale[r],
-		G: paletteScale[g],
-		B: paletteScale[b],
-		A: 255,
-	}
+	return v.palette[v.cramLatch[index&0x1F]&0x3F]
+}
+
+// BackdropColor returns the backdrop color for the current scanline,
+// selected by the latched register 7 from the sprite palette.
+func (v *VDP) BackdropColor() color.RGBA {
+	return v.cramToColor(16 + (v.reg7Latch & 0x0F))
 }
 
 // SetVBlank sets the VBlank flag in the status register
@@ -341,6 +418,7 @@ func (v *VDP) LatchVScrollForFrame() {
 // Called at CRAMLatchCycle into each scanline, after line interrupt handlers have had time to modify CRAM
 func (v *VDP) LatchCRAM() {
 	copy(v.cramLatch[:], v.cram[:])
+	v.lineWrites = v.lineWrites[:0]
 }
 
 // LatchPerLineRegisters latches per-scanline registers (hScroll, reg2, reg7)
@@ -386,6 +464,29 @@ func (v *VDP) RenderScanline() {
 		return
 	}
 
+	if v.midLineWrites && len(v.lineWrites) > 0 {
+		v.renderSplitScanline(line)
+		return
+	}
+	v.renderScanline(line)
+}
+
+// SkipScanline advances past the current scanline without drawing it,
+// for frames dropped by frameskip. Sprites are still evaluated so the
+// overflow and collision flags match a rendered line.
+func (v *VDP) SkipScanline() {
+	line := v.vCounter
+	if int(line) >= v.ActiveHeight() {
+		return
+	}
+	v.lineWrites = v.lineWrites[:0]
+	if v.register[1]&0x40 != 0 || v.blankedCollision {
+		v.renderSprites(line, false)
+	}
+}
+
+// renderScanline renders a full scanline using the latched per-line state
+func (v *VDP) renderScanline(line uint16) {
 	// Clear priority flags for this scanline
 	for i := range v.bgPriority {
 		v.bgPriority[i] = false
@@ -394,23 +495,30 @@ func (v *VDP) RenderScanline() {
 	// Check if display is enabled (register 1, bit 6)
 	if v.register[1]&0x40 == 0 {
 		// Display disabled - fill with backdrop color (using latched reg7)
-		bgColor := v.cramToColor(16 + (v.reg7Latch & 0x0F))
-		for x := 0; x < ScreenWidth; x++ {
-			v.framebuffer.SetRGBA(x, int(line), bgColor)
+		v.fillLine(line, 0, ScreenWidth, v.cramLatch[16+(v.reg7Latch&0x0F)])
+		// Sprite evaluation continues while blanked, so overflow and
+		// collision flags still update
+		if v.blankedCollision {
+			v.renderSprites(line, false)
 		}
 		return
 	}
 
 	// Render background first, then sprites on top
 	v.renderBackground(line)
-	v.renderSprites(line)
+	v.renderSprites(line, true)
 
 	// Left column blank (register 0 bit 5) - mask first 8 pixels with backdrop
 	if v.register[0]&0x20 != 0 {
-		bgColor := v.cramToColor(16 + (v.reg7Latch & 0x0F))
-		for x := 0; x < 8; x++ {
-			v.framebuffer.SetRGBA(x, int(line), bgColor)
-		}
+		v.fillLine(line, 0, 8, v.cramLatch[16+(v.reg7Latch&0x0F)])
+	}
+}
+
+// fillLine writes a solid SMS color to pixels [startX, endX) of a scanline
+func (v *VDP) fillLine(line uint16, startX, endX int, c uint8) {
+	row := v.targetRow(line)
+	for x := startX; x < endX; x++ {
+		v.putPixel(row, x, c)
 	}
 }
 
@@ -467,8 +575,8 @@ func (v *VDP) renderBackgroundTiles(line uint16, startX, endX int, hScroll, vScr
 
 	// Calculate the effective Y position with vertical scroll (constant for the zone)
 	var effectiveY uint16
-	if activeHeight == 224 {
-		// 224-line mode: 256 modulo via bitmask
+	if activeHeight != 192 {
+		// 224/240-line mode: 256 modulo via bitmask
 		effectiveY = (uint16(line) + uint16(vScroll)) & 0xFF
 	} else {
 		// 192-line mode: 224 modulo via conditional subtraction
@@ -487,9 +595,7 @@ func (v *VDP) renderBackgroundTiles(line uint16, startX, endX int, hScroll, vScr
 	rowBase := nameTableBase + tileRow*32*2
 
 	// Framebuffer direct pixel access
-	pix := v.framebuffer.Pix
-	stride := v.framebuffer.Stride
-	yOffset := int(line) * stride
+	row := v.targetRow(line)
 
 	x := startX
 	for x < endX {
@@ -523,41 +629,23 @@ func (v *VDP) renderBackgroundTiles(line uint16, startX, endX int, hScroll, vScr
 			patternLine = 7 - tileLine
 		}
 
-		// Read 4 bitplanes (once per tile)
+		// Fetch the decoded pattern line (once per tile)
 		// Each pattern is 32 bytes (8 lines x 4 bytes per line)
-		patternAddr := patternIndex*32 + patternLine*4
-		bp0 := v.vram[patternAddr&0x3FFF]
-		bp1 := v.vram[(patternAddr+1)&0x3FFF]
-		bp2 := v.vram[(patternAddr+2)&0x3FFF]
-		bp3 := v.vram[(patternAddr+3)&0x3FFF]
+		pixels := v.patternRow(patternIndex*32, patternLine)
 
 		// Render pixels from this tile
 		// Start at tilePixelStart (may be mid-tile for the first tile)
 		// End at 7 or when we reach endX
 		for tp := tilePixelStart; tp < 8 && x < endX; tp++ {
-			// Extract pixel color from the already-loaded bitplanes
-			// Bit 7 is leftmost pixel, bit 0 is rightmost
+			// Extract pixel color from the decoded pattern line
 			pixelPos := tp
 			if hFlip {
 				pixelPos = 7 - tp
 			}
-			shift := uint(7 - pixelPos)
-			colorIndex := ((bp0 >> shift) & 1) |
-				(((bp1 >> shift) & 1) << 1) |
-				(((bp2 >> shift) & 1) << 2) |
-				(((bp3 >> shift) & 1) << 3)
+			colorIndex := pixels[pixelPos]
 
 			// Get color from CRAM and write to framebuffer
-			c := v.cramLatch[(paletteOffset+colorIndex)&0x1F]
-			cr := (c >> 0) & 0x03
-			cg := (c >> 2) & 0x03
-			cb := (c >> 4) & 0x03
-
-			p := yOffset + x*4
-			pix[p] = paletteScale[cr]
-			pix[p+1] = paletteScale[cg]
-			pix[p+2] = paletteScale[cb]
-			pix[p+3] = 0xFF
+			v.putPixel(row, x, v.cramLatch[(paletteOffset+colorIndex)&0x1F])
 
 			// Track priority
 			if priority && colorIndex != 0 {
@@ -569,8 +657,20 @@ func (v *VDP) renderBackgroundTiles(line uint16, startX, endX int, hScroll, vScr
 	}
 }
 
-// renderSprites renders sprites for a scanline
-func (v *VDP) renderSprites(line uint16) {
+// spriteScreenY returns the first line a sprite is drawn on. Sprites are
+// displayed at Y+1, and the line counter wraps at 256, so a Y that would
+// place the sprite below the active area puts it partly above the top.
+func spriteScreenY(y, activeHeight int) int {
+	spriteY := y + 1
+	if spriteY >= activeHeight {
+		spriteY -= 256
+	}
+	return spriteY
+}
+
+// renderSprites renders sprites for a scanline. When draw is false only
+// the overflow and collision flags are updated.
+func (v *VDP) renderSprites(line uint16, draw bool) {
 	// Sprite Attribute Table base from register 5
 	// Bits 1-6 × $100, typically $3F00
 	satBase := uint16(v.register[5]&0x7E) << 7
@@ -599,16 +699,17 @@ func (v *VDP) renderSprites(line uint16) {
 		spriteShift = 8
 	}
 
-	// Get active height to determine sprite terminator behavior
+	// Get active height to determine sprite terminator and wrap behavior
 	activeHeight := v.ActiveHeight()
 
-	// Collect sprites on this line (max 8)
+	// Collect sprites on this line (max 8, or all 64 when the limit is removed)
 	type spriteInfo struct {
+		index   int
 		x       int
 		pattern uint8
 		line    int // Line within sprite
 	}
-	var sprites [8]spriteInfo
+	var sprites [64]spriteInfo
 	spriteCount := 0
 
 	// Scan sprite Y positions (first 64 bytes of SAT)
@@ -621,15 +722,16 @@ func (v *VDP) renderSprites(line uint16) {
 			break
 		}
 
-		// Sprite Y is actually displayed at Y+1
-		spriteY := y + 1
+		spriteY := spriteScreenY(y, activeHeight)
 
 		// Check if sprite intersects this scanline
 		if int(line) >= spriteY && int(line) < spriteY+effectiveHeight {
 			if spriteCount >= 8 {
 				// Sprite overflow - set status bit 6
 				v.status |= 0x40
-				break
+				if !v.noSpriteLimit {
+					break
+				}
 			}
 
 			// Get X and pattern from second part of SAT
@@ -646,6 +748,7 @@ func (v *VDP) renderSprites(line uint16) {
 			spriteLine := (int(line) - spriteY) >> zoomShift
 
 			sprites[spriteCount] = spriteInfo{
+				index:   i,
 				x:       spriteX,
 				pattern: pattern,
 				line:    spriteLine,
@@ -661,8 +764,12 @@ func (v *VDP) renderSprites(line uint16) {
 		v.spritePixels[i] = false
 	}
 
+	// Framebuffer direct pixel access
+	row := v.targetRow(line)
+
 	for i := spriteCount - 1; i >= 0; i-- {
 		spr := sprites[i]
+		masked := v.spriteMask&(1<<uint(spr.index)) != 0
 
 		// Determine which pattern to use (for 8x16, top or bottom half)
 		pattern := uint16(spr.pattern)
@@ -672,14 +779,8 @@ func (v *VDP) renderSprites(line uint16) {
 			spriteLine -= 8
 		}
 
-		// Get pattern address
-		patternAddr := patternBase + pattern*32 + uint16(spriteLine)*4
-
-		// Read 4 bitplanes
-		bp0 := v.vram[patternAddr&0x3FFF]
-		bp1 := v.vram[(patternAddr+1)&0x3FFF]
-		bp2 := v.vram[(patternAddr+2)&0x3FFF]
-		bp3 := v.vram[(patternAddr+3)&0x3FFF]
+		// Fetch the decoded pattern line
+		pixels := v.patternRow(patternBase+pattern*32, uint16(spriteLine))
 
 		// Render 8 pixels (or 16 if zoomed)
 		for px := 0; px < 8*zoom; px++ {
@@ -689,36 +790,50 @@ func (v *VDP) renderSprites(line uint16) {
 			}
 
 			// Get pixel from pattern (accounting for zoom)
-			patternPx := px >> zoomShift
-			shift := uint(7 - patternPx)
-			colorIndex := ((bp0 >> shift) & 1) |
-				(((bp1 >> shift) & 1) << 1) |
-				(((bp2 >> shift) & 1) << 2) |
-				(((bp3 >> shift) & 1) << 3)
+			colorIndex := pixels[px>>zoomShift]
 
 			// Color 0 is transparent
 			if colorIndex == 0 {
 				continue
 			}
 
-			// Check for sprite collision
-			if v.spritePixels[screenX] {
-				v.status |= 0x20 // Set collision flag
+			// Check for sprite collision. Only the first 8 sprites are
+			// fetched by the hardware, so extra sprites drawn with the
+			// limit removed do not participate.
+			if i < 8 {
+				if v.spritePixels[screenX] {
+					v.status |= 0x20 // Set collision flag
+				}
+				v.spritePixels[screenX] = true
 			}
-			v.spritePixels[screenX] = true
 
-			// Skip if background has priority at this pixel
-			if v.bgPriority[screenX] {
+			// Skip if not drawing, masked for debugging, or background has priority at this pixel
+			if !draw || masked || v.bgPriority[screenX] {
 				continue
 			}
 
 			// Draw sprite pixel - sprites always use CRAM 16-31 in Mode 4
-			pixelColor := v.cramToColor(colorIndex + 16)
-			v.framebuffer.SetRGBA(screenX, int(line), pixelColor)
+			v.putPixel(row, screenX, v.cramLatch[colorIndex+16])
 		}
 	}
 }
 
+// SetNoSpriteLimit enables or disables drawing sprites beyond the
+// 8-per-scanline hardware limit. The overflow status bit is still set
+// so games that poll it behave the same; only the visible flicker from
+// dropped sprites is removed.
+func (v *VDP) SetNoSpriteLimit(enabled bool) {
+	v.noSpriteLimit = enabled
+}
+
+// SetBlankedCollision enables evaluating sprites for overflow and
+// collision on lines where the display is blanked, matching hardware that
+// keeps fetching sprites while register 1 bit 6 is clear. Collision on
+// displayed lines is always checked per pixel.
+func (v *VDP) SetBlankedCollision(enabled bool) {
+	v.blankedCollision = enabled
+}
+
 // Framebuffer returns the current framebuffer
 func (v *VDP) Framebuffer() *image.RGBA {
 	return v.framebuffer
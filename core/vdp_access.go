@@ -0,0 +1,43 @@
+package core
+
+// VRAM access timing
+//
+// During active display the VDP spends most of its VRAM bandwidth fetching
+// tiles and sprites and only gives the CPU an access slot every few
+// pixels. The Z80 is never made to wait: a data port write that arrives
+// before the previous one has been stored is lost. With access timing
+// enabled, VRAM writes made too soon after the last stored write on a
+// displayed line are dropped. Blanked lines, VBlank and CRAM writes are
+// unrestricted.
+//
+// The timestamp of the last write is not saved in states. States are taken
+// between frames, where the first write of a frame is always stored, so
+// this does not affect replays.
+
+// vramWriteGap is the shortest spacing in CPU cycles between VRAM writes
+// that survives active display: an OUTI / JP NZ loop, the fastest loop
+// documented as safe. OTIR (21 cycles) and unrolled OUTI (16) lose data.
+const vramWriteGap = 26
+
+// SetAccessTiming enables dropping VRAM writes made faster than the
+// active display allows.
+func (v *VDP) SetAccessTiming(enabled bool) {
+	v.accessTiming = enabled
+	v.lastVRAMWrite = -vramWriteGap
+}
+
+// vramWriteStored reports whether a VRAM write made now gets an access
+// slot, recording it if so
+func (v *VDP) vramWriteStored() bool {
+	if !v.accessTiming {
+		return true
+	}
+	now := int(v.vCounter)*CyclesPerScanline + v.lineCycle
+	active := int(v.vCounter) < v.ActiveHeight() && v.register[1]&0x40 != 0
+	// A negative gap is a write from the previous frame
+	if gap := now - v.lastVRAMWrite; active && gap >= 0 && gap < vramWriteGap {
+		return false
+	}
+	v.lastVRAMWrite = now
+	return true
+}
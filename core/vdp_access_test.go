@@ -0,0 +1,90 @@
+package core
+
+import "testing"
+
+// writeVRAMAt writes value to the VDP data port at a cycle of the current line
+func writeVRAMAt(vdp *VDP, cycle int, value uint8) {
+	vdp.SetLineCycle(cycle)
+	vdp.WriteData(value)
+}
+
+// setupAccessTest enables the display and points the VDP at VRAM $0000
+// on an active line
+func setupAccessTest(vdp *VDP, line uint16) {
+	vdp.WriteControl(0x40)
+	vdp.WriteControl(0x81)
+	vdp.SetVCounter(line)
+	vdp.WriteControl(0x00)
+	vdp.WriteControl(0x40)
+}
+
+// TestVDP_AccessTiming_DropsFastWrites tests that VRAM writes closer than
+// vramWriteGap during active display are lost and the address still moves
+func TestVDP_AccessTiming_DropsFastWrites(t *testing.T) {
+	vdp := NewVDP()
+	vdp.SetAccessTiming(true)
+	setupAccessTest(vdp, 10)
+
+	writeVRAMAt(vdp, 20, 0x11)
+	writeVRAMAt(vdp, 36, 0x22)              // 16 cycles: OUTI speed
+	writeVRAMAt(vdp, 20+vramWriteGap, 0x33) // Gap measured from the last stored write
+
+	want := []uint8{0x11, 0x00, 0x33}
+	for i, w := range want {
+		if got := vdp.vram[i]; got != w {
+			t.Errorf("VRAM[%d]: expected 0x%02X, got 0x%02X", i, w, got)
+		}
+	}
+}
+
+// TestVDP_AccessTiming_Unrestricted tests the cases where writes are never
+// lost
+func TestVDP_AccessTiming_Unrestricted(t *testing.T) {
+	tests := []struct {
+		name   string
+		timing bool
+		line   uint16
+		blank  bool
+	}{
+		{"option off", false, 10, false},
+		{"vblank", true, 200, false},
+		{"display blanked", true, 10, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vdp := NewVDP()
+			vdp.SetAccessTiming(tc.timing)
+			setupAccessTest(vdp, tc.line)
+			if tc.blank {
+				vdp.WriteControl(0x00)
+				vdp.WriteControl(0x81)
+				vdp.WriteControl(0x00)
+				vdp.WriteControl(0x40)
+			}
+
+			for i := 0; i < 4; i++ {
+				writeVRAMAt(vdp, 20+i*16, uint8(i+1))
+			}
+			for i := 0; i < 4; i++ {
+				if got := vdp.vram[i]; got != uint8(i+1) {
+					t.Errorf("VRAM[%d]: expected 0x%02X, got 0x%02X", i, i+1, got)
+				}
+			}
+		})
+	}
+}
+
+// TestVDP_AccessTiming_NewFrame tests that the first write of a frame is
+// stored after a late write in the previous one
+func TestVDP_AccessTiming_NewFrame(t *testing.T) {
+	vdp := NewVDP()
+	vdp.SetAccessTiming(true)
+	setupAccessTest(vdp, 100)
+	writeVRAMAt(vdp, 20, 0x11)
+
+	vdp.SetVCounter(0)
+	writeVRAMAt(vdp, 20, 0x22)
+	if got := vdp.vram[1]; got != 0x22 {
+		t.Errorf("First write of a frame: expected 0x22, got 0x%02X", got)
+	}
+}
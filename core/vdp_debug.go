@@ -0,0 +1,247 @@
+package core
+
+import (
+	"image"
+	"image/color"
+)
+
+// Debug view dimensions
+const (
+	// Tile sheet: 512 patterns arranged 16 across by 32 down
+	TileSheetWidth  = 16 * 8
+	TileSheetHeight = 32 * 8
+
+	// Name table: 32 entries across by 28 rows in 192-line mode, the area
+	// reachable by its vertical scroll wrap. 224 and 240-line modes use
+	// all 32 rows and wrap at NameTableTallHeight.
+	NameTableWidth      = 32 * 8
+	NameTableHeight     = 28 * 8
+	NameTableTallHeight = 32 * 8
+
+	// Palette: 32 CRAM entries as 16x2 swatches
+	PaletteSwatchSize = 8
+	PaletteWidth      = 16 * PaletteSwatchSize
+	PaletteHeight     = 2 * PaletteSwatchSize
+)
+
+// scrollOverlayColor marks the visible viewport in RenderNameTable.
+var scrollOverlayColor = color.RGBA{R: 255, G: 0, B: 255, A: 255}
+
+// cramEntryToColor converts a raw CRAM byte to RGBA.
+// Unlike cramToColor this does not go through the per-line latch, so it
+// reflects the palette as currently written by the CPU.
+func (v *VDP) cramEntryToColor(c uint8) color.RGBA {
+	return v.palette[c&0x3F]
+}
+
+// drawPattern draws an 8x8 pattern from VRAM into img at (ox, oy).
+// paletteOffset selects CRAM 0-15 (0) or 16-31 (16).
+func (v *VDP) drawPattern(img *image.RGBA, ox, oy int, patternIndex uint16, paletteOffset uint8, hFlip, vFlip bool) {
+	for row := 0; row < 8; row++ {
+		patternLine := uint16(row)
+		if vFlip {
+			patternLine = uint16(7 - row)
+		}
+		pixels := v.patternRow(patternIndex*32, patternLine)
+
+		for col := 0; col < 8; col++ {
+			pixelPos := col
+			if hFlip {
+				pixelPos = 7 - col
+			}
+			colorIndex := pixels[pixelPos]
+			img.SetRGBA(ox+col, oy+row, v.cramEntryToColor(v.cram[(paletteOffset+colorIndex)&0x1F]))
+		}
+	}
+}
+
+// RenderTileSheet renders all 512 patterns in VRAM as a 128x256 image.
+// Patterns are laid out left to right, top to bottom. paletteSelect
+// chooses the background (0) or sprite (1) half of CRAM.
+func (v *VDP) RenderTileSheet(paletteSelect int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, TileSheetWidth, TileSheetHeight))
+	paletteOffset := uint8(0)
+	if paletteSelect != 0 {
+		paletteOffset = 16
+	}
+	for i := 0; i < 512; i++ {
+		ox := (i % 16) * 8
+		oy := (i / 16) * 8
+		v.drawPattern(img, ox, oy, uint16(i), paletteOffset, false, false)
+	}
+	return img
+}
+
+// RenderNameTable renders the background name table as a 256x224 image,
+// or 256x256 in 224 and 240-line modes, honoring each entry's pattern,
+// flip and palette bits. When showScroll is true, the area visible on
+// screen given the current scroll registers is outlined, wrapping at the
+// table edges.
+func (v *VDP) RenderNameTable(showScroll bool) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, NameTableWidth, v.nameTableHeight()))
+
+	var nameTableBase uint16
+	if v.ActiveHeight() == 192 {
+		nameTableBase = uint16(v.register[2]&0x0E) << 10
+	} else {
+		nameTableBase = (uint16(v.register[2]&0x0C) << 10) | 0x0700
+	}
+
+	for row := 0; row < img.Bounds().Dy()/8; row++ {
+		for col := 0; col < NameTableWidth/8; col++ {
+			addr := nameTableBase + uint16(row*32+col)*2
+			entryLo := v.vram[addr&0x3FFF]
+			entryHi := v.vram[(addr+1)&0x3FFF]
+
+			patternIndex := uint16(entryLo) | (uint16(entryHi&0x01) << 8)
+			hFlip := (entryHi & 0x02) != 0
+			vFlip := (entryHi & 0x04) != 0
+			paletteOffset := uint8((entryHi&0x08)>>3) * 16
+
+			v.drawPattern(img, col*8, row*8, patternIndex, paletteOffset, hFlip, vFlip)
+		}
+	}
+
+	if showScroll {
+		v.drawScrollOverlay(img)
+	}
+
+	return img
+}
+
+// nameTableHeight returns the height of the name table shown for the
+// current mode, which is also where the vertical scroll wraps
+func (v *VDP) nameTableHeight() int {
+	if v.ActiveHeight() > 192 {
+		return NameTableTallHeight
+	}
+	return NameTableHeight
+}
+
+// drawScrollOverlay outlines the on-screen viewport on a name table image.
+// Horizontal scroll moves the screen left, so the viewport starts at
+// -hScroll; vertical scroll moves it down the table.
+func (v *VDP) drawScrollOverlay(img *image.RGBA) {
+	w := NameTableWidth
+	h := v.nameTableHeight()
+	activeHeight := v.ActiveHeight()
+	left := (256 - int(v.register[8])) % w
+	top := int(v.register[9]) % h
+
+	for x := 0; x < ScreenWidth; x++ {
+		px := (left + x) % w
+		img.SetRGBA(px, top, scrollOverlayColor)
+		img.SetRGBA(px, (top+activeHeight-1)%h, scrollOverlayColor)
+	}
+	for y := 0; y < activeHeight; y++ {
+		py := (top + y) % h
+		img.SetRGBA(left, py, scrollOverlayColor)
+		img.SetRGBA((left+ScreenWidth-1)%w, py, scrollOverlayColor)
+	}
+}
+
+// RenderPalette renders the 32 CRAM entries as a 128x16 image of 8x8
+// swatches. The top row is the background palette (0-15) and the bottom
+// row is the sprite palette (16-31).
+func (v *VDP) RenderPalette() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, PaletteWidth, PaletteHeight))
+	for i := 0; i < 32; i++ {
+		c := v.cramEntryToColor(v.cram[i])
+		ox := (i % 16) * PaletteSwatchSize
+		oy := (i / 16) * PaletteSwatchSize
+		for y := 0; y < PaletteSwatchSize; y++ {
+			for x := 0; x < PaletteSwatchSize; x++ {
+				img.SetRGBA(ox+x, oy+y, c)
+			}
+		}
+	}
+	return img
+}
+
+// SpriteEntry describes one Sprite Attribute Table entry for debugging.
+type SpriteEntry struct {
+	Index    int   // SAT slot (0-63)
+	X        int   // Screen X, after the register 0 bit 3 left shift
+	Y        int   // Screen Y (SAT Y + 1)
+	Pattern  uint8 // Pattern index as stored in the SAT
+	OnScreen bool  // False if past the $D0 terminator or entirely off screen
+	Masked   bool  // Hidden via SetSpriteMasked
+}
+
+// Sprites returns all 64 SAT entries decoded with the current register
+// settings. In 192-line mode a Y of $D0 terminates the list, so that entry
+// and all entries after it are reported as off screen.
+func (v *VDP) Sprites() []SpriteEntry {
+	satBase := uint16(v.register[5]&0x7E) << 7
+
+	spriteHeight := 8
+	if v.register[1]&0x02 != 0 {
+		spriteHeight = 16
+	}
+	spriteWidth := 8
+	if v.register[1]&0x01 != 0 {
+		spriteHeight *= 2
+		spriteWidth *= 2
+	}
+
+	spriteShift := 0
+	if v.register[0]&0x08 != 0 {
+		spriteShift = 8
+	}
+
+	activeHeight := v.ActiveHeight()
+	terminated := false
+
+	entries := make([]SpriteEntry, 64)
+	for i := range entries {
+		y := int(v.vram[(satBase+uint16(i))&0x3FFF])
+		if activeHeight == 192 && y == 208 {
+			terminated = true
+		}
+
+		satAddr2 := satBase + 0x80 + uint16(i)*2
+		x := int(v.vram[satAddr2&0x3FFF]) - spriteShift
+		pattern := v.vram[(satAddr2+1)&0x3FFF]
+
+		spriteY := spriteScreenY(y, activeHeight)
+		visible := spriteY < activeHeight && spriteY+spriteHeight > 0 &&
+			x < ScreenWidth && x+spriteWidth > 0
+
+		entries[i] = SpriteEntry{
+			Index:    i,
+			X:        x,
+			Y:        spriteY,
+			Pattern:  pattern,
+			OnScreen: !terminated && visible,
+			Masked:   v.spriteMask&(1<<uint(i)) != 0,
+		}
+	}
+	return entries
+}
+
+// SetSpriteMasked hides (or restores) a single SAT entry in the rendered
+// output. Masked sprites still count toward the per-line limit and sprite
+// collision so emulated behavior is unchanged; only drawing is skipped.
+func (v *VDP) SetSpriteMasked(index int, masked bool) {
+	if index < 0 || index >= 64 {
+		return
+	}
+	if masked {
+		v.spriteMask |= 1 << uint(index)
+	} else {
+		v.spriteMask &^= 1 << uint(index)
+	}
+}
+
+// SpriteMasked reports whether a SAT entry is hidden via SetSpriteMasked.
+func (v *VDP) SpriteMasked(index int) bool {
+	if index < 0 || index >= 64 {
+		return false
+	}
+	return v.spriteMask&(1<<uint(index)) != 0
+}
+
+// ClearSpriteMask restores all masked sprites.
+func (v *VDP) ClearSpriteMask() {
+	v.spriteMask = 0
+}
@@ -0,0 +1,265 @@
+package core
+
+import (
+	"image/color"
+	"testing"
+)
+
+// writeCRAM writes a single CRAM entry via the control/data ports
+func writeCRAM(vdp *VDP, index uint8, value uint8) {
+	vdp.WriteControl(index)
+	vdp.WriteControl(0xC0)
+	vdp.WriteData(value)
+}
+
+// TestVDP_RenderPalette tests that each CRAM entry fills its swatch
+func TestVDP_RenderPalette(t *testing.T) {
+	vdp := NewVDP()
+
+	writeCRAM(vdp, 0, 0x03)  // Red
+	writeCRAM(vdp, 15, 0x0C) // Green
+	writeCRAM(vdp, 16, 0x30) // Blue
+
+	img := vdp.RenderPalette()
+	if img.Bounds().Dx() != PaletteWidth || img.Bounds().Dy() != PaletteHeight {
+		t.Fatalf("Palette size: expected %dx%d, got %v", PaletteWidth, PaletteHeight, img.Bounds())
+	}
+
+	testCases := []struct {
+		x, y     int
+		expected color.RGBA
+	}{
+		{0, 0, color.RGBA{R: 255, A: 255}},
+		{7, 7, color.RGBA{R: 255, A: 255}},
+		{15 * PaletteSwatchSize, 0, color.RGBA{G: 255, A: 255}},
+		{0, PaletteSwatchSize, color.RGBA{B: 255, A: 255}},
+		{PaletteSwatchSize, PaletteSwatchSize, color.RGBA{A: 255}},
+	}
+	for _, tc := range testCases {
+		if c := img.RGBAAt(tc.x, tc.y); c != tc.expected {
+			t.Errorf("Pixel (%d, %d): expected %v, got %v", tc.x, tc.y, tc.expected, c)
+		}
+	}
+}
+
+// TestVDP_RenderTileSheet tests pattern placement and palette selection
+func TestVDP_RenderTileSheet(t *testing.T) {
+	vdp := NewVDP()
+
+	// Pattern 17 (column 1, row 1): first line all color 1
+	vdp.vram[17*32] = 0xFF
+
+	writeCRAM(vdp, 1, 0x03)  // BG color 1 = red
+	writeCRAM(vdp, 17, 0x30) // Sprite color 1 = blue
+
+	img := vdp.RenderTileSheet(0)
+	if img.Bounds().Dx() != TileSheetWidth || img.Bounds().Dy() != TileSheetHeight {
+		t.Fatalf("Tile sheet size: expected %dx%d, got %v", TileSheetWidth, TileSheetHeight, img.Bounds())
+	}
+	red := color.RGBA{R: 255, A: 255}
+	for x := 8; x < 16; x++ {
+		if c := img.RGBAAt(x, 8); c != red {
+			t.Errorf("BG palette pixel (%d, 8): expected %v, got %v", x, red, c)
+		}
+	}
+
+	img = vdp.RenderTileSheet(1)
+	blue := color.RGBA{B: 255, A: 255}
+	if c := img.RGBAAt(8, 8); c != blue {
+		t.Errorf("Sprite palette pixel (8, 8): expected %v, got %v", blue, c)
+	}
+}
+
+// TestVDP_RenderNameTable tests entry decoding including flip bits
+func TestVDP_RenderNameTable(t *testing.T) {
+	vdp := NewVDP()
+
+	// Name table at $3800 (reg2 = 0x0E)
+	vdp.register[2] = 0x0E
+
+	// Pattern 1: top line has only the leftmost pixel set (color 1)
+	vdp.vram[1*32] = 0x80
+
+	writeCRAM(vdp, 1, 0x03)  // BG color 1 = red
+	writeCRAM(vdp, 17, 0x0C) // Sprite-half color 1 = green
+
+	// Entry (0,0): pattern 1, no flip
+	vdp.vram[0x3800] = 0x01
+	vdp.vram[0x3801] = 0x00
+	// Entry (1,0): pattern 1, hflip + palette select
+	vdp.vram[0x3802] = 0x01
+	vdp.vram[0x3803] = 0x0A
+
+	img := vdp.RenderNameTable(false)
+	if img.Bounds().Dx() != NameTableWidth || img.Bounds().Dy() != NameTableHeight {
+		t.Fatalf("Name table size: expected %dx%d, got %v", NameTableWidth, NameTableHeight, img.Bounds())
+	}
+
+	if c := img.RGBAAt(0, 0); c != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("Entry (0,0) pixel 0: expected red, got %v", c)
+	}
+	if c := img.RGBAAt(15, 0); c != (color.RGBA{G: 255, A: 255}) {
+		t.Errorf("Entry (1,0) flipped pixel 7: expected green, got %v", c)
+	}
+	if c := img.RGBAAt(8, 0); c != (color.RGBA{A: 255}) {
+		t.Errorf("Entry (1,0) flipped pixel 0: expected black, got %v", c)
+	}
+}
+
+// TestVDP_RenderNameTable_ScrollOverlay tests the viewport outline position
+func TestVDP_RenderNameTable_ScrollOverlay(t *testing.T) {
+	vdp := NewVDP()
+	vdp.register[2] = 0x0E
+	vdp.register[8] = 0xF0 // Scroll left by 16 -> viewport starts at x=16
+	vdp.register[9] = 0x08 // Viewport starts at y=8
+
+	img := vdp.RenderNameTable(true)
+
+	if c := img.RGBAAt(16, 8); c != scrollOverlayColor {
+		t.Errorf("Top-left corner (16, 8): expected overlay, got %v", c)
+	}
+	// Right edge wraps: 16 + 255 = 271 -> 15
+	if c := img.RGBAAt(15, 20); c != scrollOverlayColor {
+		t.Errorf("Right edge (15, 20): expected overlay, got %v", c)
+	}
+	// Bottom edge: 8 + 191 = 199
+	if c := img.RGBAAt(100, 199); c != scrollOverlayColor {
+		t.Errorf("Bottom edge (100, 199): expected overlay, got %v", c)
+	}
+	if c := img.RGBAAt(100, 100); c == scrollOverlayColor {
+		t.Errorf("Interior (100, 100): unexpected overlay")
+	}
+}
+
+// TestVDP_RenderNameTable_TallMode tests that 224-line mode shows all 32
+// rows and wraps the scroll overlay at 256
+func TestVDP_RenderNameTable_TallMode(t *testing.T) {
+	vdp := NewVDP()
+	vdp.register[0] = 0x06 // Mode 4 with M2
+	vdp.register[1] = 0x10 // M1: 224 lines
+	vdp.register[2] = 0x0F // Name table at $3700
+	vdp.register[9] = 0xF0 // Viewport starts at y=240
+
+	img := vdp.RenderNameTable(true)
+	if img.Bounds().Dy() != NameTableTallHeight {
+		t.Fatalf("224-line name table height: expected %d, got %d", NameTableTallHeight, img.Bounds().Dy())
+	}
+	// Top edge at 240; bottom edge wraps at 256: 240 + 223 = 463 -> 207
+	if c := img.RGBAAt(100, 240); c != scrollOverlayColor {
+		t.Errorf("Top edge (100, 240): expected overlay, got %v", c)
+	}
+	if c := img.RGBAAt(100, 207); c != scrollOverlayColor {
+		t.Errorf("Bottom edge (100, 207): expected overlay, got %v", c)
+	}
+}
+
+// setupDebugSprite enables the display, places SAT at $3F00 and writes a
+// solid color-1 pattern 0 with sprite color 1 set to red.
+func setupDebugSprite(vdp *VDP) {
+	vdp.register[1] = 0x40
+	vdp.register[5] = 0x7E
+	for line := 0; line < 8; line++ {
+		vdp.vram[line*4] = 0xFF
+	}
+	writeCRAM(vdp, 17, 0x03)
+}
+
+// TestVDP_Sprites tests SAT decoding and the on-screen flag
+func TestVDP_Sprites(t *testing.T) {
+	vdp := NewVDP()
+	setupDebugSprite(vdp)
+
+	vdp.vram[0x3F00] = 9    // Sprite 0 Y
+	vdp.vram[0x3F01] = 0xF0 // Sprite 1 Y (wraps fully above the top)
+	vdp.vram[0x3F02] = 0xD0 // Terminator
+	vdp.vram[0x3F80] = 16   // Sprite 0 X
+	vdp.vram[0x3F81] = 0x05 // Sprite 0 pattern
+
+	sprites := vdp.Sprites()
+	if len(sprites) != 64 {
+		t.Fatalf("Expected 64 entries, got %d", len(sprites))
+	}
+
+	s0 := sprites[0]
+	if s0.X != 16 || s0.Y != 10 || s0.Pattern != 0x05 || !s0.OnScreen {
+		t.Errorf("Sprite 0: got %+v", s0)
+	}
+	if sprites[1].OnScreen {
+		t.Errorf("Sprite 1 above active area should be off screen")
+	}
+	for i := 2; i < 64; i++ {
+		if sprites[i].OnScreen {
+			t.Errorf("Sprite %d after terminator should be off screen", i)
+			break
+		}
+	}
+}
+
+// TestVDP_Sprites_YWrap tests that sprites near Y=$FF are reported
+// partly above the top of the screen, as the renderer draws them
+func TestVDP_Sprites_YWrap(t *testing.T) {
+	vdp := NewVDP()
+	setupDebugSprite(vdp)
+
+	vdp.vram[0x3F00] = 0xFB // Lines -4 to 3
+	vdp.vram[0x3F01] = 0xD0
+
+	s0 := vdp.Sprites()[0]
+	if s0.Y != -4 || !s0.OnScreen {
+		t.Errorf("Sprite 0: got %+v, want Y=-4 on screen", s0)
+	}
+
+	vdp.SetVCounter(0)
+	vdp.LatchVScrollForFrame()
+	vdp.LatchCRAM()
+	vdp.LatchPerLineRegisters()
+	vdp.RenderScanline()
+
+	red := color.RGBA{R: 255, A: 255}
+	if c := vdp.Framebuffer().RGBAAt(0, 0); c != red {
+		t.Errorf("Wrapped sprite pixel (0, 0): expected red, got %v", c)
+	}
+}
+
+// TestVDP_SpriteMask tests that masked sprites are not drawn but still collide
+func TestVDP_SpriteMask(t *testing.T) {
+	vdp := NewVDP()
+	setupDebugSprite(vdp)
+
+	// Two overlapping sprites on line 10
+	vdp.vram[0x3F00] = 9
+	vdp.vram[0x3F01] = 9
+	vdp.vram[0x3F02] = 0xD0
+	vdp.vram[0x3F80] = 16
+	vdp.vram[0x3F82] = 20
+
+	vdp.SetSpriteMasked(0, true)
+	vdp.SetSpriteMasked(1, true)
+	if !vdp.SpriteMasked(0) || !vdp.Sprites()[0].Masked {
+		t.Fatalf("Sprite 0 should report masked")
+	}
+
+	vdp.SetVCounter(10)
+	vdp.LatchVScrollForFrame()
+	vdp.LatchCRAM()
+	vdp.LatchPerLineRegisters()
+	vdp.RenderScanline()
+
+	red := color.RGBA{R: 255, A: 255}
+	fb := vdp.Framebuffer()
+	for x := 16; x < 28; x++ {
+		if c := fb.RGBAAt(x, 10); c == red {
+			t.Errorf("Masked sprite drawn at (%d, 10)", x)
+			break
+		}
+	}
+	if vdp.GetStatus()&0x20 == 0 {
+		t.Errorf("Masked sprites should still set the collision flag")
+	}
+
+	vdp.ClearSpriteMask()
+	vdp.RenderScanline()
+	if c := fb.RGBAAt(16, 10); c != red {
+		t.Errorf("Unmasked sprite pixel (16, 10): expected red, got %v", c)
+	}
+}
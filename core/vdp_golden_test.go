@@ -0,0 +1,114 @@
+package core
+
+import (
+	"hash/crc32"
+	"testing"
+)
+
+// goldenScene fills VRAM, CRAM and registers with a deterministic
+// pseudo-random pattern that exercises flips, palette select, priority
+// and scrolling. reg0 and reg1 select the display mode under test.
+func goldenScene(vdp *VDP, reg0, reg1 uint8) {
+	seed := uint32(0x12345678)
+	next := func() uint8 {
+		// xorshift32
+		seed ^= seed << 13
+		seed ^= seed >> 17
+		seed ^= seed << 5
+		return uint8(seed)
+	}
+	for i := range vdp.vram {
+		vdp.vram[i] = next()
+	}
+	for i := range vdp.cram {
+		vdp.cram[i] = next() & 0x3F
+	}
+
+	vdp.register[0] = reg0
+	vdp.register[1] = reg1
+	vdp.register[2] = 0xFF // Name table $3800
+	vdp.register[5] = 0xFF // SAT $3F00
+	vdp.register[6] = 0xFF // Sprite patterns $2000
+	vdp.register[7] = 0x03
+	vdp.register[8] = 0x1D
+	vdp.register[9] = 0x47
+
+	// Keep a visible set of sprites: spread Y across the screen, no terminator
+	for i := 0; i < 64; i++ {
+		vdp.vram[0x3F00+i] = uint8(i * 3)
+	}
+}
+
+// renderGoldenFrame renders all active lines of the golden scene and
+// returns the CRC32 of the framebuffer.
+func renderGoldenFrame(vdp *VDP) uint32 {
+	vdp.LatchVScrollForFrame()
+	for line := 0; line < vdp.ActiveHeight(); line++ {
+		vdp.SetVCounter(uint16(line))
+		vdp.LatchCRAM()
+		vdp.LatchPerLineRegisters()
+		vdp.RenderScanline()
+	}
+	return crc32.ChecksumIEEE(vdp.framebuffer.Pix)
+}
+
+// TestVDP_GoldenFrame tests the renderer output against golden images.
+// The CRCs were captured from the per-pixel SetRGBA renderer and guard
+// that renderer optimizations produce identical output.
+func TestVDP_GoldenFrame(t *testing.T) {
+	testCases := []struct {
+		name string
+		reg0 uint8
+		reg1 uint8
+		crc  uint32
+	}{
+		// Left column blank, 8x16 sprites
+		{"192-line", 0x26, 0x42, 0xDDC6962A},
+		// Scroll locks, sprite shift, M2+M1 224-line, zoomed sprites
+		{"224-line zoom", 0xCE, 0x51, 0x272DA865},
+		// Display blanked
+		{"blanked", 0x04, 0x00, 0x311BA771},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vdp := NewVDP()
+			goldenScene(vdp, tc.reg0, tc.reg1)
+
+			if got := renderGoldenFrame(vdp); got != tc.crc {
+				t.Errorf("Golden frame CRC: expected 0x%08X, got 0x%08X", tc.crc, got)
+			}
+		})
+	}
+}
+
+// BenchmarkVDP_RenderFrame measures rendering all active lines of the
+// 192-line golden scene
+func BenchmarkVDP_RenderFrame(b *testing.B) {
+	vdp := NewVDP()
+	goldenScene(vdp, 0x26, 0x42)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		renderGoldenFrame(vdp)
+	}
+}
+
+// BenchmarkVDP_RenderFrameScrolling measures a frame with the horizontal
+// scroll changing every line, as in raster-split and parallax scenes
+func BenchmarkVDP_RenderFrameScrolling(b *testing.B) {
+	vdp := NewVDP()
+	goldenScene(vdp, 0x26, 0x42)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vdp.LatchVScrollForFrame()
+		for line := 0; line < vdp.ActiveHeight(); line++ {
+			vdp.register[8] = uint8(i + line)
+			vdp.SetVCounter(uint16(line))
+			vdp.LatchCRAM()
+			vdp.LatchPerLineRegisters()
+			vdp.RenderScanline()
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package core
+
+// Mid-scanline write splitting
+//
+// By default the VDP latches hScroll, register 2, register 7 and CRAM once
+// per line at CRAMLatchCycle and renders the whole line with those values.
+// Some games change scroll or palette while a line is being drawn to create
+// wobble and split-screen effects. With mid-line writes enabled, writes made
+// after the latch point are timestamped by CPU cycle and the line is
+// rendered in segments, each using the state in effect at its first pixel.
+
+// maxLineWrites bounds the number of tracked writes per scanline. A line is
+// 228 CPU cycles and an OUT takes at least 11, so a line cannot hold more.
+const maxLineWrites = 32
+
+// lineWriteKind identifies what a tracked write modified
+type lineWriteKind uint8
+
+const (
+	lineWriteRegister lineWriteKind = iota
+	lineWriteCRAM
+)
+
+// lineWrite is a register or CRAM write made during the active line
+type lineWrite struct {
+	x     int // First pixel affected by the write
+	kind  lineWriteKind
+	index uint8 // Register number or CRAM address
+	value uint8
+}
+
+// SetMidLineWrites enables or disables splitting scanline rendering at
+// the position of mid-line register and CRAM writes.
+func (v *VDP) SetMidLineWrites(enabled bool) {
+	v.midLineWrites = enabled
+	v.lineWrites = v.lineWrites[:0]
+}
+
+// SetLineCycle updates the CPU cycle within the current scanline.
+// Used to timestamp writes for mid-line rendering.
+func (v *VDP) SetLineCycle(cycle int) {
+	v.lineCycle = cycle
+}
+
+// cycleToPixel maps a CPU cycle within the scanline to the first pixel
+// drawn at or after it. Pixels are output at 2 master clocks each
+// (3 master clocks per CPU cycle) starting from the latch point.
+func cycleToPixel(cycle int) int {
+	return (cycle - CRAMLatchCycle) * 3 / 2
+}
+
+// recordLineWrite stores a write that lands inside the visible portion of
+// the current line. lineCycle is the start of the OUT instruction and the
+// write lands ioWriteCycle later, as for writeIOControl. Writes from an
+// instruction started before the latch point are picked up by the latch
+// itself, which runs between instructions; writes past the last pixel
+// take effect on the next line.
+func (v *VDP) recordLineWrite(kind lineWriteKind, index, value uint8) {
+	if !v.midLineWrites || v.lineCycle < CRAMLatchCycle {
+		return
+	}
+	x := cycleToPixel(v.lineCycle + ioWriteCycle)
+	if x >= ScreenWidth || len(v.lineWrites) >= maxLineWrites {
+		return
+	}
+	v.lineWrites = append(v.lineWrites, lineWrite{
+		x:     x,
+		kind:  kind,
+		index: index,
+		value: value,
+	})
+}
+
+// applyLineWrite updates the latched rendering state with a tracked write
+func (v *VDP) applyLineWrite(w lineWrite) {
+	if w.kind == lineWriteCRAM {
+		v.cramLatch[w.index&0x1F] = w.value
+		return
+	}
+	switch w.index {
+	case 2:
+		v.reg2Latch = w.value
+	case 7:
+		v.reg7Latch = w.value
+	case 8:
+		v.hScrollLatch = w.value
+	}
+}
+
+// renderSplitScanline renders the line once per segment between tracked
+// writes and assembles the segments into the framebuffer row. Rendering
+// the full line for each segment keeps sprite and priority handling
+// identical to the normal path.
+func (v *VDP) renderSplitScanline(line uint16) {
+	row := v.targetRow(line)
+	bpp := v.bytesPerPixel()
+
+	// Writes arrive in cycle order so x is already non-decreasing
+	start := 0
+	i := 0
+	for start < ScreenWidth {
+		for i < len(v.lineWrites) && v.lineWrites[i].x <= start {
+			v.applyLineWrite(v.lineWrites[i])
+			i++
+		}
+		end := ScreenWidth
+		if i < len(v.lineWrites) {
+			end = v.lineWrites[i].x
+		}
+
+		v.renderScanline(line)
+		copy(v.lineBuffer[start*bpp:end*bpp], row[start*bpp:end*bpp])
+		start = end
+	}
+
+	copy(row, v.lineBuffer[:len(row)])
+	v.lineWrites = v.lineWrites[:0]
+}
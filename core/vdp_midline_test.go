@@ -0,0 +1,139 @@
+package core
+
+import (
+	"image/color"
+	"testing"
+)
+
+// setupMidLineCRAMTest enables the display with an all-zero name table so
+// every background pixel uses CRAM entry 0, set to red and latched.
+func setupMidLineCRAMTest(vdp *VDP) {
+	vdp.WriteControl(0x40)
+	vdp.WriteControl(0x81)
+	vdp.WriteControl(0xFF)
+	vdp.WriteControl(0x82) // Name table at $3800
+
+	writeCRAM(vdp, 0, 0x03) // Red
+
+	vdp.SetVCounter(0)
+	vdp.LatchVScrollForFrame()
+	vdp.SetLineCycle(CRAMLatchCycle)
+	vdp.LatchCRAM()
+	vdp.LatchPerLineRegisters()
+}
+
+// TestVDP_MidLineWrites_CRAMSplit tests that a CRAM write mid-line only
+// affects pixels drawn after it
+func TestVDP_MidLineWrites_CRAMSplit(t *testing.T) {
+	vdp := NewVDP()
+	vdp.SetMidLineWrites(true)
+	setupMidLineCRAMTest(vdp)
+
+	// Write lands 64 cycles after the latch point -> pixel 96
+	vdp.SetLineCycle(CRAMLatchCycle + 64 - ioWriteCycle)
+	writeCRAM(vdp, 0, 0x0C) // Green
+
+	vdp.RenderScanline()
+
+	fb := vdp.Framebuffer()
+	red := color.RGBA{R: 255, A: 255}
+	green := color.RGBA{G: 255, A: 255}
+	if c := fb.RGBAAt(95, 0); c != red {
+		t.Errorf("Pixel 95 before write: expected red, got %v", c)
+	}
+	if c := fb.RGBAAt(96, 0); c != green {
+		t.Errorf("Pixel 96 after write: expected green, got %v", c)
+	}
+	if c := fb.RGBAAt(255, 0); c != green {
+		t.Errorf("Pixel 255 after write: expected green, got %v", c)
+	}
+}
+
+// TestVDP_MidLineWrites_Disabled tests the default per-line latch behavior
+func TestVDP_MidLineWrites_Disabled(t *testing.T) {
+	vdp := NewVDP()
+	setupMidLineCRAMTest(vdp)
+
+	vdp.SetLineCycle(CRAMLatchCycle + 64)
+	writeCRAM(vdp, 0, 0x0C)
+
+	vdp.RenderScanline()
+
+	red := color.RGBA{R: 255, A: 255}
+	if c := vdp.Framebuffer().RGBAAt(200, 0); c != red {
+		t.Errorf("Pixel 200 with mid-line writes disabled: expected red, got %v", c)
+	}
+}
+
+// TestVDP_MidLineWrites_HScroll tests a scroll change splitting the line
+func TestVDP_MidLineWrites_HScroll(t *testing.T) {
+	vdp := NewVDP()
+	vdp.SetMidLineWrites(true)
+	setupMidLineCRAMTest(vdp)
+
+	// Tile column 1 uses pattern 1 (solid color 1 = blue); others pattern 0
+	vdp.vram[0x3802] = 0x01
+	for i := 0; i < 8; i++ {
+		vdp.vram[32+i*4] = 0xFF
+	}
+	writeCRAM(vdp, 1, 0x30)
+	vdp.LatchCRAM()
+
+	// Scroll right by 8 at pixel 12: tile column 1 moves to x=16-23
+	vdp.SetLineCycle(CRAMLatchCycle + 8 - ioWriteCycle)
+	vdp.WriteControl(0x08)
+	vdp.WriteControl(0x88)
+
+	vdp.RenderScanline()
+
+	fb := vdp.Framebuffer()
+	blue := color.RGBA{B: 255, A: 255}
+	red := color.RGBA{R: 255, A: 255}
+	if c := fb.RGBAAt(8, 0); c != blue {
+		t.Errorf("Pixel 8 before scroll write: expected blue, got %v", c)
+	}
+	if c := fb.RGBAAt(13, 0); c != red {
+		t.Errorf("Pixel 13 after scroll write: expected red, got %v", c)
+	}
+	if c := fb.RGBAAt(16, 0); c != blue {
+		t.Errorf("Pixel 16 after scroll write: expected blue, got %v", c)
+	}
+}
+
+// TestVDP_MidLineWrites_BeforeLatch tests that writes before the latch
+// point are left to the normal latch
+func TestVDP_MidLineWrites_BeforeLatch(t *testing.T) {
+	vdp := NewVDP()
+	vdp.SetMidLineWrites(true)
+
+	// The write itself lands after the latch point, but the latch runs
+	// after this instruction and picks it up
+	vdp.SetLineCycle(CRAMLatchCycle - 1)
+	writeCRAM(vdp, 0, 0x03)
+	if len(vdp.lineWrites) != 0 {
+		t.Errorf("Write before latch should not be tracked, got %d", len(vdp.lineWrites))
+	}
+
+	vdp.SetLineCycle(CRAMLatchCycle + 200) // Past pixel 255
+	writeCRAM(vdp, 0, 0x03)
+	if len(vdp.lineWrites) != 0 {
+		t.Errorf("Write after active display should not be tracked, got %d", len(vdp.lineWrites))
+	}
+}
+
+// TestVDP_MidLineWrites_WriteCycle tests that a write is placed at the
+// cycle the OUT instruction writes, not the cycle it starts
+func TestVDP_MidLineWrites_WriteCycle(t *testing.T) {
+	vdp := NewVDP()
+	vdp.SetMidLineWrites(true)
+
+	vdp.SetLineCycle(CRAMLatchCycle + 40)
+	writeCRAM(vdp, 0, 0x03)
+	if len(vdp.lineWrites) != 1 {
+		t.Fatalf("Expected 1 tracked write, got %d", len(vdp.lineWrites))
+	}
+	want := cycleToPixel(CRAMLatchCycle + 40 + ioWriteCycle)
+	if x := vdp.lineWrites[0].x; x != want {
+		t.Errorf("Write pixel: expected %d, got %d", want, x)
+	}
+}
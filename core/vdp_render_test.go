@@ -162,6 +162,11 @@ func TestVDP_RenderBackground_BasicTile(t *testing.T) {
 	vdp.WriteControl(0x0E)
 	vdp.WriteControl(0x82)
 
+	// Move the sprite table off the tile data so its bytes are not
+	// read as sprite Y positions
+	vdp.WriteControl(0x7E)
+	vdp.WriteControl(0x85)
+
 	// Set up a simple tile pattern at index 0 (address $0000)
 	// Pattern is 8x8, 4 bytes per line (4bpp)
 	// Let's make a solid color tile (all pixels = color 1)
@@ -289,6 +294,8 @@ func TestVDP_RenderBackground_VerticalFlip(t *testing.T) {
 
 	vdp.WriteControl(0x0E)
 	vdp.WriteControl(0x82)
+	vdp.WriteControl(0x7E)
+	vdp.WriteControl(0x85)
 
 	// Create a tile where line 0 is color 1, line 7 is color 2
 	vdp.WriteControl(0x00)
@@ -535,6 +542,48 @@ func TestVDP_RenderSprites_Collision(t *testing.T) {
 	}
 }
 
+// TestVDP_RenderSprites_CollisionWhileBlanked tests that collision is only
+// evaluated on blanked lines when blanked collision is enabled
+func TestVDP_RenderSprites_CollisionWhileBlanked(t *testing.T) {
+	vdp := NewVDP()
+	setupDebugSprite(vdp)
+
+	// Two overlapping sprites on line 10
+	vdp.vram[0x3F00] = 9
+	vdp.vram[0x3F01] = 9
+	vdp.vram[0x3F02] = 0xD0
+	vdp.vram[0x3F80] = 16
+	vdp.vram[0x3F82] = 20
+
+	// Blank the display
+	vdp.register[1] &^= 0x40
+
+	render := func() {
+		vdp.ReadControl()
+		vdp.SetVCounter(10)
+		vdp.LatchCRAM()
+		vdp.LatchPerLineRegisters()
+		vdp.RenderScanline()
+	}
+
+	render()
+	if vdp.GetStatus()&0x20 != 0 {
+		t.Error("Collision flag should not be set on a blanked line by default")
+	}
+
+	vdp.SetBlankedCollision(true)
+	render()
+	if vdp.GetStatus()&0x20 == 0 {
+		t.Error("Collision flag should be set on a blanked line with blanked collision enabled")
+	}
+
+	// Blanked line stays backdrop colored
+	red := color.RGBA{R: 255, A: 255}
+	if c := vdp.Framebuffer().RGBAAt(20, 10); c == red {
+		t.Error("Sprites should not be drawn while the display is blanked")
+	}
+}
+
 // TestVDP_RenderSprites_Overflow tests sprite overflow detection
 func TestVDP_RenderSprites_Overflow(t *testing.T) {
 	vdp := NewVDP()
@@ -596,6 +645,79 @@ func TestVDP_RenderSprites_Overflow(t *testing.T) {
 	}
 }
 
+// TestVDP_RenderSprites_NoSpriteLimit tests drawing past 8 sprites per line
+func TestVDP_RenderSprites_NoSpriteLimit(t *testing.T) {
+	vdp := NewVDP()
+
+	// Enable display, SAT at $3F00, sprite patterns at $0000
+	vdp.WriteControl(0x40)
+	vdp.WriteControl(0x81)
+	vdp.WriteControl(0x7E)
+	vdp.WriteControl(0x85)
+	vdp.WriteControl(0x00)
+	vdp.WriteControl(0x86)
+
+	// Create sprite pattern
+	vdp.WriteControl(0x00)
+	vdp.WriteControl(0x40)
+	for line := 0; line < 8; line++ {
+		vdp.WriteData(0xFF)
+		vdp.WriteData(0x00)
+		vdp.WriteData(0x00)
+		vdp.WriteData(0x00)
+	}
+
+	// 10 sprites on line 10
+	vdp.WriteControl(0x00)
+	vdp.WriteControl(0x7F)
+	for i := 0; i < 10; i++ {
+		vdp.WriteData(0x09)
+	}
+	vdp.WriteData(0xD0)
+
+	vdp.WriteControl(0x80)
+	vdp.WriteControl(0x7F)
+	for i := 0; i < 10; i++ {
+		vdp.WriteData(uint8(i * 16))
+		vdp.WriteData(0x00)
+	}
+
+	// Sprite color 1 = red
+	vdp.WriteControl(17)
+	vdp.WriteControl(0xC0)
+	vdp.WriteData(0x03)
+
+	render := func() {
+		vdp.ReadControl()
+		vdp.SetVCounter(10)
+		vdp.LatchVScrollForFrame()
+		vdp.LatchCRAM()
+		vdp.LatchPerLineRegisters()
+		vdp.RenderScanline()
+	}
+
+	fb := vdp.Framebuffer()
+	red := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+
+	// With the limit, the 9th sprite (X=128) is dropped
+	render()
+	if c := fb.RGBAAt(128, 10); c == red {
+		t.Errorf("9th sprite should not be drawn with the limit enabled")
+	}
+
+	// Without the limit, all 10 are drawn and overflow is still reported
+	vdp.SetNoSpriteLimit(true)
+	render()
+	for _, x := range []int{128, 144} {
+		if c := fb.RGBAAt(x, 10); c != red {
+			t.Errorf("Sprite pixel (%d, 10): expected red, got %v", x, c)
+		}
+	}
+	if vdp.GetStatus()&0x40 == 0 {
+		t.Error("Sprite overflow flag should still be set with the limit removed")
+	}
+}
+
 // TestVDP_RenderSprites_Height16 tests 8x16 sprite mode
 func TestVDP_RenderSprites_Height16(t *testing.T) {
 	vdp := NewVDP()
@@ -751,6 +873,76 @@ func TestVDP_RenderSprites_Terminator(t *testing.T) {
 	}
 }
 
+// TestVDP_RenderSprites_TopWrap tests that a sprite whose Y places it below
+// the active area wraps to the top of the screen, as the line counter does
+func TestVDP_RenderSprites_TopWrap(t *testing.T) {
+	tests := []struct {
+		name string
+		reg0 uint8
+		reg1 uint8
+	}{
+		{"192-line", 0x04, 0x40},
+		{"224-line", 0x06, 0x50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vdp := NewVDP()
+
+			vdp.WriteControl(tt.reg0)
+			vdp.WriteControl(0x80)
+			vdp.WriteControl(tt.reg1)
+			vdp.WriteControl(0x81)
+
+			vdp.WriteControl(0x7E)
+			vdp.WriteControl(0x85)
+
+			vdp.WriteControl(0x00)
+			vdp.WriteControl(0x86)
+
+			// Create sprite pattern
+			vdp.WriteControl(0x00)
+			vdp.WriteControl(0x40)
+			for line := 0; line < 8; line++ {
+				vdp.WriteData(0xFF)
+				vdp.WriteData(0x00)
+				vdp.WriteData(0x00)
+				vdp.WriteData(0x00)
+			}
+
+			// Sprite 0 at Y=$FB covers lines -4 to 3
+			vdp.WriteControl(0x00)
+			vdp.WriteControl(0x7F)
+			vdp.WriteData(0xFB)
+			vdp.WriteData(0xD0)
+
+			vdp.WriteControl(0x80)
+			vdp.WriteControl(0x7F)
+			vdp.WriteData(0x10) // X = 16
+			vdp.WriteData(0x00)
+
+			vdp.WriteControl(17)
+			vdp.WriteControl(0xC0)
+			vdp.WriteData(0x03)
+
+			redColor := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+			fb := vdp.Framebuffer()
+			vdp.LatchVScrollForFrame()
+			vdp.LatchCRAM()
+			for _, line := range []uint16{0, 3, 4} {
+				vdp.SetVCounter(line)
+				vdp.LatchPerLineRegisters()
+				vdp.RenderScanline()
+
+				c := fb.RGBAAt(16, int(line))
+				if want := line < 4; (c == redColor) != want {
+					t.Errorf("Line %d pixel 16: sprite drawn = %v, want %v", line, c == redColor, want)
+				}
+			}
+		})
+	}
+}
+
 // TestVDP_RenderSprites_Zoom tests zoomed sprites (2x size)
 func TestVDP_RenderSprites_Zoom(t *testing.T) {
 	vdp := NewVDP()
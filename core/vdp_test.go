@@ -333,7 +333,7 @@ func TestVDP_LineCounterBehavior(t *testing.T) {
 }
 
 // TestVDP_ActiveHeight tests 192/224 line modes
-// Note: 240-line mode (M2=1, M1=0) is Game Gear only, not supported on SMS
+// Note: 240-line mode (M2=1, M3=1) is PAL only; see TestVDP_ActiveHeight_240PAL
 func TestVDP_ActiveHeight(t *testing.T) {
 	vdp := NewVDP()
 
@@ -504,11 +504,11 @@ func TestVDP_FramebufferNotNil(t *testing.T) {
 		t.Error("Framebuffer should not be nil")
 	}
 
-	// Framebuffer is sized for maximum possible height (224) to support all display modes
-	// (192-line standard, 224-line extended)
+	// Framebuffer is sized for maximum possible height (240) to support all display modes
+	// (192-line standard, 224-line extended, 240-line PAL extended)
 	bounds := fb.Bounds()
-	if bounds.Dx() != 256 || bounds.Dy() != 224 {
-		t.Errorf("Framebuffer size: expected 256x224 (MaxScreenHeight), got %dx%d", bounds.Dx(), bounds.Dy())
+	if bounds.Dx() != 256 || bounds.Dy() != 240 {
+		t.Errorf("Framebuffer size: expected 256x240 (MaxScreenHeight), got %dx%d", bounds.Dx(), bounds.Dy())
 	}
 }
 
@@ -654,6 +654,73 @@ func TestVDP_VCounter_PAL224(t *testing.T) {
 	}
 }
 
+// TestVDP_ActiveHeight_240PAL tests that 240-line mode is honored only on PAL
+func TestVDP_ActiveHeight_240PAL(t *testing.T) {
+	vdp := NewVDP()
+
+	// M2=1 (reg0 bit 1), M3=1 (reg1 bit 3)
+	vdp.WriteControl(0x02)
+	vdp.WriteControl(0x80)
+	vdp.WriteControl(0x08)
+	vdp.WriteControl(0x81)
+
+	if !vdp.Requested240LineMode() {
+		t.Error("Requested240LineMode should be true with M2=1, M3=1")
+	}
+
+	// NTSC falls back to 192 lines
+	if got := vdp.ActiveHeight(); got != 192 {
+		t.Errorf("NTSC 240-line request: expected 192, got %d", got)
+	}
+
+	vdp.SetTotalScanlines(313)
+	if got := vdp.ActiveHeight(); got != 240 {
+		t.Errorf("PAL 240-line mode: expected 240, got %d", got)
+	}
+
+	// M1+M3 both set falls back to 224 via M1
+	vdp.WriteControl(0x18)
+	vdp.WriteControl(0x81)
+	if vdp.Requested240LineMode() {
+		t.Error("Requested240LineMode should be false with M1 set")
+	}
+	if got := vdp.ActiveHeight(); got != 224 {
+		t.Errorf("M1+M3: expected 224, got %d", got)
+	}
+}
+
+// TestVDP_VCounter_PAL240 tests V-counter behavior in PAL 240-line mode
+func TestVDP_VCounter_PAL240(t *testing.T) {
+	vdp := NewVDP()
+	vdp.SetTotalScanlines(313) // PAL
+
+	vdp.WriteControl(0x02)
+	vdp.WriteControl(0x80)
+	vdp.WriteControl(0x08)
+	vdp.WriteControl(0x81)
+
+	testCases := []struct {
+		line     uint16
+		expected uint8
+	}{
+		{0, 0},
+		{239, 239},
+		{255, 255},
+		{256, 0},   // Wraps through zero
+		{266, 10},  // Last normal line ($0A)
+		{267, 210}, // Jump: 267 - 57 = 210 ($D2)
+		{312, 255}, // Last line
+	}
+
+	for _, tc := range testCases {
+		vdp.SetVCounter(tc.line)
+		got := vdp.ReadVCounter()
+		if got != tc.expected {
+			t.Errorf("PAL 240-line V-counter at line %d: expected %d, got %d", tc.line, tc.expected, got)
+		}
+	}
+}
+
 // TestVDP_VCounter_SetTotalScanlines tests region configuration
 func TestVDP_VCounter_SetTotalScanlines(t *testing.T) {
 	vdp := NewVDP()
@@ -0,0 +1,59 @@
+package core
+
+// tileCount is the number of 32-byte patterns that fit in VRAM
+const tileCount = 0x4000 / 32
+
+// tileCache holds VRAM patterns decoded from 4bpp bitplanes into one
+// palette index per pixel. A pattern is marked dirty when any of its 32
+// bytes is written and decoded again the next time it is rendered, so
+// static tiles are decoded once instead of once per scanline.
+type tileCache struct {
+	pixels [tileCount][8][8]uint8
+	dirty  [tileCount]bool
+}
+
+// invalidateAll marks every pattern for decoding. Used after VRAM is
+// replaced wholesale, such as when loading a save state.
+func (c *tileCache) invalidateAll() {
+	for i := range c.dirty {
+		c.dirty[i] = true
+	}
+}
+
+// invalidate marks the pattern containing a VRAM address as dirty
+func (c *tileCache) invalidate(addr uint16) {
+	c.dirty[(addr&0x3FFF)>>5] = true
+}
+
+// patternRow returns the decoded palette indices for one line of a pattern,
+// leftmost pixel first. patternAddr is the VRAM address of the pattern.
+func (v *VDP) patternRow(patternAddr uint16, line uint16) *[8]uint8 {
+	tile := (patternAddr & 0x3FFF) >> 5
+	if v.tiles.dirty[tile] {
+		v.decodeTile(tile)
+	}
+	return &v.tiles.pixels[tile][line&7]
+}
+
+// decodeTile converts a pattern's bitplanes to palette indices
+func (v *VDP) decodeTile(tile uint16) {
+	base := tile * 32
+	for line := uint16(0); line < 8; line++ {
+		addr := base + line*4
+		bp0 := v.vram[addr]
+		bp1 := v.vram[addr+1]
+		bp2 := v.vram[addr+2]
+		bp3 := v.vram[addr+3]
+
+		row := &v.tiles.pixels[tile][line]
+		for px := 0; px < 8; px++ {
+			// Bit 7 is leftmost pixel, bit 0 is rightmost
+			shift := uint(7 - px)
+			row[px] = ((bp0 >> shift) & 1) |
+				(((bp1 >> shift) & 1) << 1) |
+				(((bp2 >> shift) & 1) << 2) |
+				(((bp3 >> shift) & 1) << 3)
+		}
+	}
+	v.tiles.dirty[tile] = false
+}
@@ -0,0 +1,68 @@
+package core
+
+import "testing"
+
+// TestVDP_TileCache_Decode tests bitplane decoding into palette indices
+func TestVDP_TileCache_Decode(t *testing.T) {
+	vdp := NewVDP()
+
+	// Pattern 3, line 2: plane 0 = 0x80, plane 1 = 0xC0, plane 3 = 0x01
+	vdp.WriteControl(0x68)
+	vdp.WriteControl(0x40) // VRAM write at 3*32 + 2*4 = $0068
+	vdp.WriteData(0x80)
+	vdp.WriteData(0xC0)
+	vdp.WriteData(0x00)
+	vdp.WriteData(0x01)
+
+	row := vdp.patternRow(3*32, 2)
+	expected := [8]uint8{3, 2, 0, 0, 0, 0, 0, 8}
+	if *row != expected {
+		t.Errorf("Decoded row: expected %v, got %v", expected, *row)
+	}
+}
+
+// TestVDP_TileCache_InvalidateOnWrite tests that a VRAM write after a
+// pattern was decoded is reflected on the next fetch
+func TestVDP_TileCache_InvalidateOnWrite(t *testing.T) {
+	vdp := NewVDP()
+
+	if row := vdp.patternRow(0x2000, 7); row[0] != 0 {
+		t.Fatalf("Initial pixel: expected 0, got %d", row[0])
+	}
+
+	// Plane 2 of pattern $100 line 7 ($201E)
+	vdp.WriteControl(0x1E)
+	vdp.WriteControl(0x60)
+	vdp.WriteData(0x80)
+
+	if row := vdp.patternRow(0x2000, 7); row[0] != 4 {
+		t.Errorf("Pixel after write: expected 4, got %d", row[0])
+	}
+}
+
+// TestEmulator_TileCache_LoadState tests that loading a save state
+// invalidates patterns decoded from the previous VRAM contents
+func TestEmulator_TileCache_LoadState(t *testing.T) {
+	e := createTestEmulator()
+
+	e.vdp.vram[0] = 0xFF
+	e.vdp.tiles.invalidate(0)
+	state, err := e.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	e.vdp.WriteControl(0x00)
+	e.vdp.WriteControl(0x40)
+	e.vdp.WriteData(0x00)
+	if row := e.vdp.patternRow(0, 0); row[0] != 0 {
+		t.Fatalf("Pixel before load: expected 0, got %d", row[0])
+	}
+
+	if err := e.Deserialize(state); err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+	if row := e.vdp.patternRow(0, 0); row[0] != 1 {
+		t.Errorf("Pixel after load: expected 1, got %d", row[0])
+	}
+}
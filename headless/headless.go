@@ -0,0 +1,155 @@
+// Package headless runs the emulator without a display, audio device or
+// input hardware. It has no ebiten or cgo dependency, so it can be
+// embedded in servers, bots and test harnesses.
+//
+//	emu, err := headless.LoadFile("game.sms")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	emu.SetInput(0, headless.Button1)
+//	emu.RunFrames(60)
+//	img := emu.Screenshot()
+package headless
+
+import (
+	"image"
+	"io"
+	"os"
+
+	"github.com/user-none/emkiii/core"
+)
+
+// Buttons is a controller state bitmask. It uses the same bit layout as
+// coreif so values can be passed through from an eblitui frontend.
+type Buttons uint32
+
+// Controller buttons. Pause is on the console and is read from player 0.
+const (
+	Up      Buttons = 1 << 0
+	Down    Buttons = 1 << 1
+	Left    Buttons = 1 << 2
+	Right   Buttons = 1 << 3
+	Button1 Buttons = 1 << 4
+	Button2 Buttons = 1 << 5
+	Pause   Buttons = 1 << 7
+)
+
+// Emulator is a headless Master System. It is not safe for concurrent use.
+type Emulator struct {
+	emu   core.Emulator
+	input [2]Buttons
+	audio []int16
+}
+
+// Load creates an emulator running the given ROM image.
+func Load(rom []byte) (*Emulator, error) {
+	emu, err := core.NewEmulator(rom)
+	if err != nil {
+		return nil, err
+	}
+	return &Emulator{emu: emu}, nil
+}
+
+// LoadFile reads a ROM from disk and creates an emulator running it.
+func LoadFile(path string) (*Emulator, error) {
+	rom, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Load(rom)
+}
+
+// SetOption applies a core option using the same keys and values as the
+// frontends, such as "video_standard" = "pal" or "no_sprite_limit" = "true".
+func (e *Emulator) SetOption(key, value string) {
+	e.emu.SetOption(key, value)
+}
+
+// SetDebugConsole sends text the ROM prints on the SDSC debug console to
+// w. nil disables the console.
+func (e *Emulator) SetDebugConsole(w io.Writer) {
+	e.emu.SetDebugConsole(w)
+}
+
+// SetInput sets the buttons held by a player (0 or 1). The state is held
+// until changed and applied to every following frame. Pause triggers once
+// per press, so it must be released before it can trigger again.
+func (e *Emulator) SetInput(player int, buttons Buttons) {
+	if player < 0 || player >= len(e.input) {
+		return
+	}
+	e.input[player] = buttons
+}
+
+// RunFrames runs n frames. Audio produced by them is appended to the
+// buffer returned by Audio.
+func (e *Emulator) RunFrames(n int) {
+	for i := 0; i < n; i++ {
+		for player, buttons := range e.input {
+			e.emu.SetInput(player, uint32(buttons))
+		}
+		e.emu.RunFrame()
+		e.audio = append(e.audio, e.emu.GetAudioSamples()...)
+	}
+}
+
+// Audio returns the 16-bit stereo samples produced since the last call
+// and clears the buffer.
+func (e *Emulator) Audio() []int16 {
+	samples := e.audio
+	e.audio = nil
+	return samples
+}
+
+// SampleRate returns the audio sample rate in Hz.
+func (e *Emulator) SampleRate() int {
+	return core.SampleRate
+}
+
+// FPS returns the frame rate of the current video standard.
+func (e *Emulator) FPS() int {
+	return e.emu.GetTiming().FPS
+}
+
+// Screenshot returns a copy of the current frame, including the effect
+// of video options such as crop border, overscan and filters.
+func (e *Emulator) Screenshot() image.Image {
+	pixels, width, height := e.Frame()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	copy(img.Pix, pixels)
+	return img
+}
+
+// Frame returns the current frame as RGBA rows of width*4 bytes without
+// copying. The data is overwritten by the next RunFrames.
+func (e *Emulator) Frame() (pixels []byte, width, height int) {
+	stride := e.emu.GetFramebufferStride()
+	height = e.emu.GetActiveHeight()
+	return e.emu.GetFramebuffer()[:stride*height], stride / 4, height
+}
+
+// SaveState returns a save state compatible with the frontends.
+func (e *Emulator) SaveState() ([]byte, error) {
+	return e.emu.Serialize()
+}
+
+// LoadState restores a state produced by SaveState or a frontend.
+func (e *Emulator) LoadState(state []byte) error {
+	return e.emu.Deserialize(state)
+}
+
+// ReadMemory copies system RAM ($C000-$DFFF) starting at offset addr into
+// buf and returns the number of bytes read.
+func (e *Emulator) ReadMemory(addr uint32, buf []byte) int {
+	return int(e.emu.ReadMemory(addr, buf))
+}
+
+// SRAM returns a copy of the cartridge RAM.
+func (e *Emulator) SRAM() []byte {
+	return e.emu.GetSRAM()
+}
+
+// SetSRAM loads cartridge RAM, such as a battery save from a frontend.
+func (e *Emulator) SetSRAM(data []byte) {
+	e.emu.SetSRAM(data)
+}
@@ -0,0 +1,75 @@
+package headless
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+// testROM returns a 32KB ROM that loops forever
+func testROM() []byte {
+	rom := make([]byte, 0x8000)
+	copy(rom, []byte{0xF3, 0x18, 0xFE}) // DI; JR $
+	return rom
+}
+
+// TestRunFramesAndScreenshot tests frame output and audio collection
+func TestRunFramesAndScreenshot(t *testing.T) {
+	emu, err := Load(testROM())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	emu.RunFrames(3)
+
+	img, ok := emu.Screenshot().(*image.RGBA)
+	if !ok {
+		t.Fatal("Screenshot should be an *image.RGBA")
+	}
+	if got := img.Bounds(); got != image.Rect(0, 0, 256, 192) {
+		t.Errorf("Screenshot bounds: expected 256x192, got %v", got)
+	}
+
+	samples := emu.Audio()
+	perFrame := emu.SampleRate() / emu.FPS() * 2
+	if len(samples) < 3*perFrame-6 || len(samples) > 3*perFrame+6 {
+		t.Errorf("Audio after 3 frames: expected about %d samples, got %d", 3*perFrame, len(samples))
+	}
+	if len(emu.Audio()) != 0 {
+		t.Error("Audio should be cleared after it is read")
+	}
+}
+
+// TestStateRoundTrip tests that a loaded state replays deterministically
+func TestStateRoundTrip(t *testing.T) {
+	emu, err := Load(testROM())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	emu.RunFrames(1)
+	state, err := emu.SaveState()
+	if err != nil {
+		t.Fatalf("SaveState failed: %v", err)
+	}
+
+	emu.RunFrames(2)
+	after, _ := emu.SaveState()
+	if err := emu.LoadState(state); err != nil {
+		t.Fatalf("LoadState failed: %v", err)
+	}
+	emu.RunFrames(2)
+	replay, _ := emu.SaveState()
+	if !bytes.Equal(after, replay) {
+		t.Error("Replaying from a loaded state should reproduce the same state")
+	}
+}
+
+// TestSetInputIgnoresInvalidPlayer tests that out of range players are ignored
+func TestSetInputIgnoresInvalidPlayer(t *testing.T) {
+	emu, err := Load(testROM())
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	emu.SetInput(2, Button1)
+	emu.SetInput(-1, Button1)
+	emu.RunFrames(1)
+}
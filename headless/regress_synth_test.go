@@ -0,0 +1,161 @@
+//go:build regress
+
+package headless
+
+// Synthetic test ROMs for the regression harness. They are built here so
+// the goldens always have something to check, even without the homebrew
+// test ROMs. Each ROM sets up the VDP from data tables, turns the display
+// on and spins with interrupts disabled, so the final frame depends only
+// on the renderer.
+
+// synthROMs maps goldens file names to the ROMs built for them
+var synthROMs = map[string]func() []byte{
+	"synth-background": synthBackgroundROM,
+	"synth-sprites":    synthSpritesROM,
+}
+
+// Where the VDP data tables are placed in the ROM
+const synthDataBase = 0x1000
+
+// synthAsm assembles a setup program followed by its VDP data tables
+type synthAsm struct {
+	code []byte
+	data []byte
+}
+
+// out emits LD A,value / OUT (port),A
+func (a *synthAsm) out(port, value uint8) {
+	a.code = append(a.code, 0x3E, value, 0xD3, port)
+}
+
+// reg sets a VDP register
+func (a *synthAsm) reg(r, value uint8) {
+	a.out(0xBF, value)
+	a.out(0xBF, 0x80|r)
+}
+
+// copyTo sets the VDP address (code 1 for VRAM, 3 for CRAM) and writes
+// data to the data port with OTIR, 256 bytes at a time
+func (a *synthAsm) copyTo(addr uint16, code uint8, data []byte) {
+	a.out(0xBF, uint8(addr))
+	a.out(0xBF, uint8(addr>>8)&0x3F|code<<6)
+
+	src := synthDataBase + len(a.data)
+	a.data = append(a.data, data...)
+	a.code = append(a.code, 0x21, uint8(src), uint8(src>>8)) // LD HL,src
+	a.code = append(a.code, 0x0E, 0xBE)                      // LD C,$BE
+	for n := len(data); n > 0; n -= 256 {
+		a.code = append(a.code, 0x06, uint8(n)) // LD B,n (0 = 256)
+		a.code = append(a.code, 0xED, 0xB3)     // OTIR
+	}
+}
+
+// rom returns a 32KB ROM running the program and then spinning
+func (a *synthAsm) rom() []byte {
+	rom := make([]byte, 0x8000)
+	prog := append([]byte{
+		0xF3,       // DI
+		0xED, 0x56, // IM 1
+		0x31, 0xF0, 0xDF, // LD SP,$DFF0
+	}, a.code...)
+	prog = append(prog, 0x18, 0xFE) // JR $
+	copy(rom, prog)
+	copy(rom[synthDataBase:], a.data)
+	return rom
+}
+
+// synthPalette returns 32 CRAM entries covering a spread of colors
+func synthPalette() []byte {
+	cram := make([]byte, 32)
+	for i := range cram {
+		cram[i] = uint8(i*11+5) & 0x3F
+	}
+	cram[16] = 0x00 // Sprite palette color 0 is transparent; keep it dark
+	return cram
+}
+
+// synthTiles returns n 4bpp tiles of distinct, asymmetric patterns so
+// flips and palette selection change the output
+func synthTiles(n int) []byte {
+	tiles := make([]byte, n*32)
+	for i := range tiles {
+		tile, row, plane := i/32, i%32/4, i%4
+		tiles[i] = uint8(tile*37+row*11+plane*73) ^ uint8(0x80>>row)
+	}
+	return tiles
+}
+
+// synthBackgroundROM draws a full name table using flips, both palettes
+// and priority, with fine scroll and the left column blanked
+func synthBackgroundROM() []byte {
+	var a synthAsm
+	a.reg(0, 0x26) // Mode 4, left column blank
+	a.reg(1, 0x80)
+	a.reg(2, 0xFF) // Name table at $3800
+	a.reg(5, 0xFF) // Sprite attribute table at $3F00
+	a.reg(6, 0xFB)
+	a.reg(7, 0x03) // Backdrop color 3
+	a.reg(8, 13)   // X scroll
+	a.reg(9, 21)   // Y scroll
+	a.reg(10, 0xFF)
+
+	a.copyTo(0x0000, 3, synthPalette())
+	a.copyTo(0x0000, 1, synthTiles(32))
+
+	names := make([]byte, 32*28*2)
+	for i := 0; i < 32*28; i++ {
+		names[i*2] = uint8(i*7) % 32
+		names[i*2+1] = uint8(i/3) & 0x1E // Flips, palette and priority bits
+	}
+	a.copyTo(0x3800, 1, names)
+	a.copyTo(0x3F00, 1, []byte{0xD0}) // No sprites
+
+	a.reg(1, 0xC0) // Display on
+	return a.rom()
+}
+
+// synthSpritesROM draws sprites over a plain background: 8x16 sprites,
+// overlaps, more than eight on one line, sprites off the left and bottom
+// edges and the $D0 terminator
+func synthSpritesROM() []byte {
+	var a synthAsm
+	a.reg(0, 0x0E) // Mode 4, shift sprites left 8 pixels
+	a.reg(1, 0x80)
+	a.reg(2, 0xFF) // Name table at $3800
+	a.reg(5, 0xFF) // Sprite attribute table at $3F00
+	a.reg(6, 0xFB) // Sprite patterns at $0000
+	a.reg(7, 0x01)
+	a.reg(8, 0)
+	a.reg(9, 0)
+	a.reg(10, 0xFF)
+
+	a.copyTo(0x0000, 3, synthPalette())
+	tiles := synthTiles(32)
+	clear(tiles[:32]) // Blank tile 0 for the background
+	a.copyTo(0x0000, 1, tiles)
+	a.copyTo(0x3800, 1, make([]byte, 32*28*2)) // Tile 0 everywhere
+
+	const count = 24
+	ys := make([]byte, 64)
+	xn := make([]byte, 128)
+	for i := 0; i < count; i++ {
+		ys[i] = uint8(20 + i*7)
+		xn[i*2] = uint8(i * 10)
+		xn[i*2+1] = uint8(2 + i*2%30) // Tiles 2-31; tile 0 is blank
+	}
+	for i := 0; i < 10; i++ { // Ten sprites on the same lines
+		ys[i] = 100
+		xn[i*2] = uint8(20 + i*20)
+	}
+	ys[count-1] = 186   // Runs off the bottom
+	xn[(count-2)*2] = 2 // Runs off the left edge after the shift
+	ys[count] = 0xD0    // Terminator; the sprite after it is not drawn
+	ys[count+1] = 50
+	xn[(count+1)*2] = 128
+	xn[(count+1)*2+1] = 4
+	a.copyTo(0x3F00, 1, ys)
+	a.copyTo(0x3F80, 1, xn)
+
+	a.reg(1, 0xC2) // Display on, 8x16 sprites
+	return a.rom()
+}
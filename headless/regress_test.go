@@ -0,0 +1,147 @@
+//go:build regress
+
+package headless
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var updateGoldens = flag.Bool("update", false, "record golden CRCs from the current build")
+
+const goldensPath = "testdata/regress.txt"
+
+// golden is one line of the goldens file
+type golden struct {
+	rom    string
+	frames int
+	crc    string // 8 hex digits, or "-" when not recorded
+}
+
+// readGoldens parses the goldens file, keeping comment lines so the file
+// can be rewritten by -update
+func readGoldens(t *testing.T) ([]golden, []string) {
+	t.Helper()
+	f, err := os.Open(goldensPath)
+	if err != nil {
+		t.Fatalf("open goldens: %v", err)
+	}
+	defer f.Close()
+
+	var entries []golden
+	var header []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			if len(entries) == 0 {
+				header = append(header, sc.Text())
+			}
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			t.Fatalf("goldens: malformed line %q", line)
+		}
+		frames, err := strconv.Atoi(fields[1])
+		if err != nil {
+			t.Fatalf("goldens: bad frame count in %q", line)
+		}
+		entries = append(entries, golden{rom: fields[0], frames: frames, crc: fields[2]})
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("read goldens: %v", err)
+	}
+	return entries, header
+}
+
+// writeGoldens rewrites the goldens file with updated CRCs
+func writeGoldens(t *testing.T, entries []golden, header []string) {
+	t.Helper()
+	var b strings.Builder
+	for _, line := range header {
+		b.WriteString(line + "\n")
+	}
+	for _, g := range entries {
+		fmt.Fprintf(&b, "%-20s  %-6d  %s\n", g.rom, g.frames, g.crc)
+	}
+	if err := os.WriteFile(goldensPath, []byte(b.String()), 0o644); err != nil {
+		t.Fatalf("write goldens: %v", err)
+	}
+}
+
+// frameCRC runs a ROM for the given number of frames and returns the CRC32
+// of the final frame
+func frameCRC(t *testing.T, emu *Emulator, frames int) string {
+	t.Helper()
+	emu.RunFrames(frames)
+	img := emu.Screenshot().(*image.RGBA)
+	return fmt.Sprintf("%08X", crc32.ChecksumIEEE(img.Pix))
+}
+
+// loadRegressROM loads a goldens entry, building synthetic ROMs in
+// process. ok is false when the ROM file is not available.
+func loadRegressROM(t *testing.T, romDir, name string) (emu *Emulator, ok bool) {
+	t.Helper()
+	var err error
+	if build, synth := synthROMs[name]; synth {
+		emu, err = Load(build())
+	} else {
+		path := filepath.Join(romDir, name)
+		if _, statErr := os.Stat(path); statErr != nil {
+			return nil, false
+		}
+		emu, err = LoadFile(path)
+	}
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return emu, true
+}
+
+// TestRegressGoldenFrames runs each test ROM and compares its final frame
+// with the recorded golden
+func TestRegressGoldenFrames(t *testing.T) {
+	romDir := os.Getenv("EMKIII_REGRESS_ROMS")
+	if romDir == "" {
+		romDir = filepath.Join("testdata", "roms")
+	}
+
+	entries, header := readGoldens(t)
+	updated := false
+	for i := range entries {
+		g := &entries[i]
+		t.Run(g.rom, func(t *testing.T) {
+			emu, ok := loadRegressROM(t, romDir, g.rom)
+			if !ok {
+				t.Skipf("ROM not available: %s", filepath.Join(romDir, g.rom))
+			}
+
+			got := frameCRC(t, emu, g.frames)
+			switch {
+			case *updateGoldens:
+				if got != g.crc {
+					t.Logf("recorded %s (was %s)", got, g.crc)
+					g.crc = got
+					updated = true
+				}
+			case g.crc == "-":
+				t.Errorf("no golden recorded (got %s); run with -update", got)
+			case got != g.crc:
+				t.Errorf("frame %d CRC: expected %s, got %s", g.frames, g.crc, got)
+			}
+		})
+	}
+
+	if updated {
+		writeGoldens(t, entries, header)
+	}
+}
@@ -0,0 +1,61 @@
+//go:build regress
+
+package headless
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var zexallFrames = flag.Int("zexall.frames", 1000000, "frame limit for the ZEXALL/ZEXDOC runs")
+
+// TestRegressZexall boots the SMS ports of ZEXDOC and ZEXALL and reads
+// their results from the SDSC debug console. A full run takes many
+// minutes, so it is skipped with -short and needs -timeout 0:
+//
+//	go test -tags regress -timeout 0 -run Zexall ./headless/
+func TestRegressZexall(t *testing.T) {
+	if testing.Short() {
+		t.Skip("ZEXALL is a long test")
+	}
+	romDir := os.Getenv("EMKIII_REGRESS_ROMS")
+	if romDir == "" {
+		romDir = filepath.Join("testdata", "roms")
+	}
+
+	for _, name := range []string{"zexdoc.sms", "zexall.sms"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(romDir, name)
+			emu, err := LoadFile(path)
+			if err != nil {
+				t.Skipf("ROM not available: %v", err)
+			}
+			// Results come from the console, so skip drawing most frames
+			emu.SetOption("frameskip", "3")
+
+			var out strings.Builder
+			emu.SetDebugConsole(&out)
+			frames := 0
+			for frames < *zexallFrames {
+				emu.RunFrames(60)
+				frames += 60
+				if strings.Contains(strings.ToLower(out.String()), "tests complete") {
+					break
+				}
+			}
+			t.Logf("%s after %d frames:\n%s", name, frames, out.String())
+
+			if !strings.Contains(strings.ToLower(out.String()), "tests complete") {
+				t.Fatalf("did not finish within %d frames", *zexallFrames)
+			}
+			for _, line := range strings.Split(out.String(), "\n") {
+				if strings.Contains(strings.ToUpper(line), "ERROR") {
+					t.Errorf("opcode test failed: %s", strings.TrimSpace(line))
+				}
+			}
+		})
+	}
+}